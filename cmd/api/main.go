@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/Agero19/AnnotateX-api/internal/config"
 	"github.com/Agero19/AnnotateX-api/internal/db"
 	"github.com/Agero19/AnnotateX-api/internal/logger"
 	"github.com/Agero19/AnnotateX-api/internal/repository"
 	"github.com/Agero19/AnnotateX-api/internal/server"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	os.Exit(run())
+}
 
+// run starts the API server and returns the process exit code, so that deferred
+// cleanup such as closing the database runs before main exits.
+func run() int {
 	//load env by godotenv
 	_ = godotenv.Load()
 	// This is the entry point for the API server.
@@ -38,18 +48,39 @@ func main() {
 
 	if err != nil {
 		log.Error("Failed to connect to the database", "error", err)
-		os.Exit(1)
+		return 1
 	}
-	defer db.Close()
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("Failed to close the database", "error", err)
+			return
+		}
+		log.Info("Database connection closed")
+	}()
 
 	// Initialize repository
 	repo := repository.NewRepository(db)
 
+	// Initialize blob storage for uploaded files
+	storageCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	blob, err := storage.Open(storageCtx, cfg)
+	cancel()
+	if err != nil {
+		log.Error("Failed to initialize storage", "backend", cfg.Storage.Backend, "error", err)
+		return 1
+	}
+
+	// Stop on SIGINT/SIGTERM: the server drains in-flight requests and background workers before Run returns
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Start the API server
-	app := server.NewApp(cfg, repo, log)
+	app := server.NewApp(cfg, repo, blob, log)
 	mux := app.Mount()
-	//TODO: Implement graceful shutdown for the server
-	if err := app.Run(mux); err != nil {
+	app.Background("thumbnails", app.Thumbnails.Run)
+	if err := app.Run(ctx, mux); err != nil {
 		log.Error("Failed to run API server", "error", err)
+		return 1
 	}
+	return 0
 }
@@ -0,0 +1,196 @@
+// Command coco exports a project as a COCO detection dataset, or imports one into a project:
+//
+//	coco export -project 12 -o instances.json
+//	coco import -project 12 -user 3 -i instances.json [-dry-run]
+//
+// Exports are streamed, so projects of any size export in constant memory. Imports are
+// written in a single transaction on behalf of the given user, and are refused if the
+// file has conflicts; -dry-run only reports what would be imported.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Agero19/AnnotateX-api/internal/config"
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	"github.com/Agero19/AnnotateX-api/internal/db"
+	"github.com/Agero19/AnnotateX-api/internal/logger"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/joho/godotenv"
+)
+
+// errUsage is returned by a command whose arguments are missing or invalid.
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	os.Exit(run())
+}
+
+// run executes the command and returns the process exit code, so that deferred
+// cleanup such as closing the database runs before main exits.
+func run() int {
+	if len(os.Args) < 2 || (os.Args[1] != "export" && os.Args[1] != "import") {
+		usage()
+		return 2
+	}
+
+	_ = godotenv.Load()
+
+	cfg := config.LoadConfig()
+	log := logger.SetupLogger(cfg.Env)
+
+	db, err := db.New(
+		cfg.DB.URL,
+		cfg.DB.MaxOpenConns,
+		cfg.DB.MaxIdleConns,
+		cfg.DB.MaxIdleTime,
+	)
+	if err != nil {
+		log.Error("Failed to connect to the database", "error", err)
+		return 1
+	}
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if os.Args[1] == "export" {
+		err = export(ctx, repo, log, os.Args[2:])
+	} else {
+		err = importFile(ctx, repo, cfg.Storage.MaxPixels, log, os.Args[2:])
+	}
+	if errors.Is(err, errUsage) {
+		usage()
+		return 2
+	}
+	if err != nil {
+		log.Error("Command failed", "command", os.Args[1], "error", err)
+		return 1
+	}
+	return 0
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: coco export -project ID -o FILE")
+	fmt.Fprintln(os.Stderr, "       coco import -project ID -user ID -i FILE [-dry-run]")
+}
+
+// export writes the project to the output file, which is removed again if the export fails.
+func export(ctx context.Context, repo repository.Repository, log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	projectID := fs.String("project", "", "ID of the project to export")
+	output := fs.String("o", "", "file to write the COCO JSON to")
+	if err := fs.Parse(args); err != nil || *projectID == "" || *output == "" {
+		return errUsage
+	}
+
+	project, err := repo.Projects.GetByID(ctx, *projectID)
+	if err != nil {
+		return fmt.Errorf("get project: %w", err)
+	}
+	labels, err := repo.Labels.GetByProjectID(ctx, project.ID, true)
+	if err != nil {
+		return fmt.Errorf("list labels: %w", err)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	images, annotations, err := dataset.ExportCOCO(ctx, f, repo.Images, repo.Annotations, project, labels)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*output)
+		return err
+	}
+
+	log.Info(
+		"Project exported",
+		slog.String("project_id", project.ID),
+		slog.String("format", "coco"),
+		slog.String("output", *output),
+		slog.Int("images", images),
+		slog.Int("annotations", annotations),
+	)
+	return nil
+}
+
+// importFile plans the import of a COCO file and writes it unless it is a dry run or has conflicts.
+func importFile(ctx context.Context, repo repository.Repository, maxPixels int, log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	projectID := fs.String("project", "", "ID of the project to import into")
+	userID := fs.String("user", "", "ID of the user the images and annotations are created for")
+	input := fs.String("i", "", "COCO JSON file to import")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without writing anything")
+	if err := fs.Parse(args); err != nil || *projectID == "" || *userID == "" || *input == "" {
+		return errUsage
+	}
+
+	project, err := repo.Projects.GetByID(ctx, *projectID)
+	if err != nil {
+		return fmt.Errorf("get project: %w", err)
+	}
+	if _, err := repo.Users.GetByID(ctx, *userID); err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	f, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	var coco dataset.COCO
+	err = json.NewDecoder(bufio.NewReader(f)).Decode(&coco)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", *input, err)
+	}
+
+	plan, err := dataset.PlanCOCOImport(ctx, &coco, project, *userID, maxPixels, repo.Images, repo.Annotations, repo.Labels)
+	if err != nil {
+		return fmt.Errorf("plan import: %w", err)
+	}
+
+	report := plan.Report
+	for _, c := range report.Conflicts {
+		log.Warn("Conflict", slog.String("item", c.Item), slog.String("code", c.Code), slog.String("message", c.Message))
+	}
+	for _, name := range report.UnmatchedImages {
+		log.Warn("Unmatched image", slog.String("file_name", name))
+	}
+	summary := []any{
+		slog.String("project_id", project.ID),
+		slog.Int("labels_matched", report.LabelsMatched),
+		slog.Int("labels_created", report.LabelsCreated),
+		slog.Int("images_matched", report.ImagesMatched),
+		slog.Int("images_created", report.ImagesCreated),
+		slog.Int("annotations", report.Annotations),
+		slog.Int("skipped_annotations", report.SkippedAnnotations),
+		slog.Int("conflicts", report.ConflictCount),
+	}
+
+	if *dryRun {
+		log.Info("Dry run, nothing imported", summary...)
+		return nil
+	}
+	if report.ConflictCount > 0 {
+		return fmt.Errorf("%d conflicts, nothing imported", report.ConflictCount)
+	}
+	if err := repo.Imports.Import(ctx, &plan.Batch); err != nil {
+		return err
+	}
+	log.Info("COCO file imported", summary...)
+	return nil
+}
@@ -0,0 +1 @@
+drop table refresh_tokens;
\ No newline at end of file
@@ -0,0 +1 @@
+drop table image_members;
\ No newline at end of file
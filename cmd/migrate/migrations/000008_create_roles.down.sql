@@ -0,0 +1,9 @@
+ALTER TABLE annotations
+DROP COLUMN reviewed_at,
+DROP COLUMN reviewed_by,
+DROP COLUMN status;
+
+drop table user_roles;
+drop table role_permissions;
+drop table permissions;
+drop table roles;
@@ -0,0 +1,48 @@
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE TABLE role_permissions (
+    role_id INT NOT NULL,
+    permission_id INT NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions (id) ON DELETE CASCADE
+);
+
+CREATE TABLE user_roles (
+    user_id INT NOT NULL,
+    role_id INT NOT NULL,
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE
+);
+
+INSERT INTO roles (name) VALUES ('admin'), ('reviewer'), ('annotator');
+
+INSERT INTO permissions (name) VALUES
+    ('users:read'),
+    ('users:manage'),
+    ('annotations:write'),
+    ('annotations:review');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin'
+   OR (r.name = 'reviewer' AND p.name = 'annotations:review')
+   OR (r.name = 'annotator' AND p.name = 'annotations:write');
+
+-- every existing user keeps the ability to annotate
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u, roles r WHERE r.name = 'annotator';
+
+ALTER TABLE annotations
+ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'pending',
+ADD COLUMN reviewed_by INT REFERENCES users (id) ON DELETE SET NULL,
+ADD COLUMN reviewed_at TIMESTAMP;
@@ -0,0 +1,3 @@
+ALTER TABLE images
+DROP COLUMN object_key,
+ALTER COLUMN url TYPE VARCHAR(255);
@@ -0,0 +1,3 @@
+ALTER TABLE images
+ADD COLUMN object_key VARCHAR(255) UNIQUE,
+ALTER COLUMN url TYPE TEXT;
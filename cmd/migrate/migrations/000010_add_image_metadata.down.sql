@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_images_mime_type;
+DROP INDEX IF EXISTS idx_images_dimensions;
+
+ALTER TABLE images
+DROP COLUMN orientation,
+DROP COLUMN camera_model,
+DROP COLUMN camera_make,
+DROP COLUMN captured_at,
+DROP COLUMN color_model,
+DROP COLUMN size_bytes,
+DROP COLUMN mime_type,
+DROP COLUMN height,
+DROP COLUMN width;
@@ -0,0 +1,13 @@
+ALTER TABLE images
+ADD COLUMN width INT CHECK (width > 0),
+ADD COLUMN height INT CHECK (height > 0),
+ADD COLUMN mime_type VARCHAR(100),
+ADD COLUMN size_bytes BIGINT CHECK (size_bytes >= 0),
+ADD COLUMN color_model VARCHAR(32),
+ADD COLUMN captured_at TIMESTAMP(0) WITH TIME ZONE,
+ADD COLUMN camera_make VARCHAR(255),
+ADD COLUMN camera_model VARCHAR(255),
+ADD COLUMN orientation SMALLINT CHECK (orientation BETWEEN 1 AND 8);
+
+CREATE INDEX idx_images_dimensions ON images (width, height);
+CREATE INDEX idx_images_mime_type ON images (mime_type);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_images_thumbnail_pending;
+
+ALTER TABLE images
+DROP COLUMN thumbnails,
+DROP COLUMN thumbnail_status;
@@ -0,0 +1,8 @@
+ALTER TABLE images
+ADD COLUMN thumbnail_status VARCHAR(16) CHECK (thumbnail_status IN ('pending', 'ready', 'failed')),
+ADD COLUMN thumbnails JSONB;
+
+-- uploads made before thumbnails existed are picked up by the worker
+UPDATE images SET thumbnail_status = 'pending' WHERE object_key IS NOT NULL;
+
+CREATE INDEX idx_images_thumbnail_pending ON images (created_at) WHERE thumbnail_status = 'pending';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS images_content_hash_key;
+ALTER TABLE images DROP COLUMN content_hash;
+
+DROP INDEX IF EXISTS idx_images_object_key;
+ALTER TABLE images ADD CONSTRAINT images_object_key_key UNIQUE (object_key);
@@ -0,0 +1,7 @@
+-- uploads are content addressed, so several images may share one stored file
+ALTER TABLE images DROP CONSTRAINT images_object_key_key;
+CREATE INDEX idx_images_object_key ON images (object_key);
+
+-- images uploaded before hashing keep a NULL hash and are never matched as duplicates
+ALTER TABLE images ADD COLUMN content_hash CHAR(64);
+CREATE UNIQUE INDEX images_content_hash_key ON images (user_id, content_hash);
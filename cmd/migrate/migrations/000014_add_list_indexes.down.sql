@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_annotations_created_at;
+DROP INDEX IF EXISTS idx_annotations_image_id_created_at;
+DROP INDEX IF EXISTS idx_annotations_image_id;
+DROP INDEX IF EXISTS idx_images_user_id;
+DROP INDEX IF EXISTS idx_images_created_at;
+DROP INDEX IF EXISTS idx_users_created_at;
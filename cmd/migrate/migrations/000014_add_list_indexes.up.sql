@@ -0,0 +1,7 @@
+-- keyset pagination orders by (sort field, id)
+CREATE INDEX idx_users_created_at ON users (created_at, id);
+CREATE INDEX idx_images_created_at ON images (created_at, id);
+CREATE INDEX idx_images_user_id ON images (user_id, id);
+CREATE INDEX idx_annotations_image_id ON annotations (image_id, id);
+CREATE INDEX idx_annotations_image_id_created_at ON annotations (image_id, created_at, id);
+CREATE INDEX idx_annotations_created_at ON annotations (created_at, id);
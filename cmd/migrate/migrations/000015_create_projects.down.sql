@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS images_project_content_hash_key;
+DROP INDEX IF EXISTS images_content_hash_key;
+-- the same file may be in several projects of one user; keep the hash on the oldest
+-- image only, the others are then treated like uploads from before hashing
+UPDATE images SET content_hash = NULL
+WHERE content_hash IS NOT NULL AND id NOT IN (
+    SELECT MIN(id) FROM images WHERE content_hash IS NOT NULL GROUP BY user_id, content_hash
+);
+CREATE UNIQUE INDEX images_content_hash_key ON images (user_id, content_hash);
+
+DROP INDEX IF EXISTS idx_images_project_id;
+ALTER TABLE images DROP COLUMN project_id;
+
+DROP TABLE IF EXISTS project_members;
+DROP TABLE IF EXISTS projects;
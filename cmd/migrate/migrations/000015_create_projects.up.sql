@@ -0,0 +1,29 @@
+CREATE TABLE projects (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_by INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE project_members (
+    project_id INT NOT NULL,
+    user_id INT NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, user_id),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_project_members_user_id ON project_members (user_id, project_id);
+
+-- images outside any project stay personal to their uploader
+ALTER TABLE images ADD COLUMN project_id INT REFERENCES projects (id) ON DELETE CASCADE;
+CREATE INDEX idx_images_project_id ON images (project_id, id);
+
+-- identical uploads are detected per project, or per user for personal images
+DROP INDEX images_content_hash_key;
+CREATE UNIQUE INDEX images_content_hash_key ON images (user_id, content_hash) WHERE project_id IS NULL;
+CREATE UNIQUE INDEX images_project_content_hash_key ON images (project_id, content_hash) WHERE project_id IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE annotations DROP COLUMN IF EXISTS label_id;
+DROP TABLE IF EXISTS labels;
@@ -0,0 +1,20 @@
+CREATE TABLE labels (
+    id SERIAL PRIMARY KEY,
+    project_id INT NOT NULL,
+    parent_id INT,
+    name VARCHAR(255) NOT NULL,
+    color CHAR(7) NOT NULL DEFAULT '#808080',
+    description TEXT NOT NULL DEFAULT '',
+    archived_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES labels (id) ON DELETE SET NULL
+);
+
+-- archived labels free their name for reuse
+CREATE UNIQUE INDEX labels_name_key ON labels (project_id, name) WHERE archived_at IS NULL;
+CREATE INDEX idx_labels_project_id ON labels (project_id, id);
+CREATE INDEX idx_labels_parent_id ON labels (parent_id);
+
+ALTER TABLE annotations ADD COLUMN label_id INT REFERENCES labels (id) ON DELETE SET NULL;
+CREATE INDEX idx_annotations_label_id ON annotations (label_id, id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_annotations_geometry_type;
+ALTER TABLE annotations DROP COLUMN IF EXISTS geometry;
@@ -0,0 +1,4 @@
+-- NULL keeps the box in x, y, width and height; other shapes store their points here
+-- and the bounding box in the rectangle columns
+ALTER TABLE annotations ADD COLUMN geometry JSONB CHECK (geometry IS NULL OR geometry ? 'type');
+CREATE INDEX idx_annotations_geometry_type ON annotations ((COALESCE(geometry->>'type', 'box')), id);
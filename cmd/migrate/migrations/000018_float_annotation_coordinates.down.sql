@@ -0,0 +1,6 @@
+-- rounds sub-pixel coordinates to whole pixels
+ALTER TABLE annotations
+    ALTER COLUMN x TYPE INT USING round(x),
+    ALTER COLUMN y TYPE INT USING round(y),
+    ALTER COLUMN width TYPE INT USING round(width),
+    ALTER COLUMN height TYPE INT USING round(height);
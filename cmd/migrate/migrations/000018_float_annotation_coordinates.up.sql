@@ -0,0 +1,5 @@
+ALTER TABLE annotations
+    ALTER COLUMN x TYPE DOUBLE PRECISION,
+    ALTER COLUMN y TYPE DOUBLE PRECISION,
+    ALTER COLUMN width TYPE DOUBLE PRECISION,
+    ALTER COLUMN height TYPE DOUBLE PRECISION;
@@ -0,0 +1,7 @@
+-- pixels of mask annotations in COCO's compressed run-length encoding
+CREATE TABLE annotation_masks (
+    annotation_id INT PRIMARY KEY REFERENCES annotations(id) ON DELETE CASCADE,
+    height INT NOT NULL CHECK (height > 0),
+    width INT NOT NULL CHECK (width > 0),
+    counts TEXT NOT NULL
+);
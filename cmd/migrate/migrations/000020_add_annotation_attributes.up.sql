@@ -0,0 +1,2 @@
+-- free-form flags of an annotation, e.g. Pascal VOC's difficult and truncated
+ALTER TABLE annotations ADD COLUMN attributes JSONB;
@@ -0,0 +1,79 @@
+// Command thumbnails regenerates the thumbnails of uploaded images, e.g. after
+// the thumbnail sizes changed or images failed to process. By default only
+// images without ready thumbnails are processed; -all processes every upload.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Agero19/AnnotateX-api/internal/config"
+	"github.com/Agero19/AnnotateX-api/internal/db"
+	"github.com/Agero19/AnnotateX-api/internal/logger"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+	"github.com/Agero19/AnnotateX-api/internal/thumbnail"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run regenerates the thumbnails and returns the process exit code, so that the
+// database is closed before main exits.
+func run() int {
+	all := flag.Bool("all", false, "regenerate thumbnails of every uploaded image, not only missing or failed ones")
+	flag.Parse()
+
+	_ = godotenv.Load()
+
+	cfg := config.LoadConfig()
+	log := logger.SetupLogger(cfg.Env)
+
+	db, err := db.New(
+		cfg.DB.URL,
+		cfg.DB.MaxOpenConns,
+		cfg.DB.MaxIdleConns,
+		cfg.DB.MaxIdleTime,
+	)
+	if err != nil {
+		log.Error("Failed to connect to the database", "error", err)
+		return 1
+	}
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+
+	storageCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	blob, err := storage.Open(storageCtx, cfg)
+	cancel()
+	if err != nil {
+		log.Error("Failed to initialize storage", "backend", cfg.Storage.Backend, "error", err)
+		return 1
+	}
+
+	// an interrupted run leaves the remaining images pending for the API worker
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	queued, err := repo.Images.ResetThumbnails(ctx, *all)
+	if err != nil {
+		log.Error("Failed to queue images", "error", err)
+		return 1
+	}
+	log.Info("Images queued for thumbnail generation", "count", queued)
+
+	worker := thumbnail.NewWorker(repo.Images, blob, log, cfg.Thumbnails.PollInterval, cfg.Thumbnails.BatchSize)
+	processed, err := worker.ProcessPending(ctx)
+	if err != nil {
+		log.Error("Failed to generate thumbnails", "error", err, "processed", processed)
+		return 1
+	}
+	log.Info("Thumbnails regenerated", "processed", processed)
+	return 0
+}
@@ -0,0 +1,416 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// COCOUnlabeledID is the category ID of annotations without a label; label IDs start at 1.
+const COCOUnlabeledID = 0
+
+// COCO is a dataset in the COCO object detection format. Exports are streamed with
+// COCOWriter rather than built in memory.
+type COCO struct {
+	Info        COCOInfo         `json:"info"`
+	Images      []COCOImage      `json:"images"`
+	Annotations []COCOAnnotation `json:"annotations"`
+	Categories  []COCOCategory   `json:"categories"`
+}
+
+type COCOInfo struct {
+	Description string `json:"description"`
+	DateCreated string `json:"date_created"`
+}
+
+type COCOImage struct {
+	ID       int64  `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	URL      string `json:"coco_url,omitempty"`
+}
+
+// COCOCategory is a label. Supercategory is the name of the parent label, if any, and
+// Keypoints names the landmarks of the category's keypoint annotations in array order.
+type COCOCategory struct {
+	ID            int64    `json:"id"`
+	Name          string   `json:"name"`
+	Supercategory string   `json:"supercategory"`
+	Keypoints     []string `json:"keypoints,omitempty"`
+}
+
+// COCOAnnotation is an object instance. BBox is [x, y, width, height] in pixels and
+// Keypoints holds an x, y, visibility triple per keypoint of the category.
+type COCOAnnotation struct {
+	ID           int64            `json:"id"`
+	ImageID      int64            `json:"image_id"`
+	CategoryID   int64            `json:"category_id"`
+	BBox         [4]float64       `json:"bbox"`
+	Area         float64          `json:"area"`
+	IsCrowd      int              `json:"iscrowd"`
+	Segmentation COCOSegmentation `json:"segmentation"`
+	Keypoints    []float64        `json:"keypoints,omitempty"`
+	NumKeypoints int              `json:"num_keypoints,omitempty"`
+}
+
+// COCOSegmentation is either a list of polygons, each as flat x, y pairs, or an RLE mask.
+type COCOSegmentation struct {
+	Polygons [][]float64
+	RLE      *rle.RLE
+}
+
+// MarshalJSON writes the RLE object if there is a mask and the polygon list otherwise.
+func (s COCOSegmentation) MarshalJSON() ([]byte, error) {
+	if s.RLE != nil {
+		return json.Marshal(s.RLE)
+	}
+	if s.Polygons == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s.Polygons)
+}
+
+// UnmarshalJSON reads an RLE object or a polygon list.
+func (s *COCOSegmentation) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '{':
+		s.RLE = new(rle.RLE)
+		return json.Unmarshal(data, s.RLE)
+	default:
+		return json.Unmarshal(data, &s.Polygons)
+	}
+}
+
+// ExportCOCO streams the project's images and annotations to w as a COCO dataset with a
+// category per label, and returns how many images and annotations it wrote. Labels should
+// include archived ones so that annotations still tagged with them keep their category.
+func ExportCOCO(
+	ctx context.Context,
+	w io.Writer,
+	images repository.Images,
+	annotations repository.Annotations,
+	project *repository.Project,
+	labels []*repository.Label,
+) (imageCount, annotationCount int, err error) {
+	info := COCOInfo{Description: project.Name, DateCreated: time.Now().UTC().Format(time.RFC3339)}
+	cw, err := NewCOCOWriter(w, info, labels)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cw.Close()
+
+	err = EachImage(ctx, images, annotations, project.ID, true, func(image *repository.Image, list []*repository.Annotation, masks map[string]*rle.RLE) error {
+		return cw.AddImage(image, list, masks)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := cw.Finish(); err != nil {
+		return 0, 0, err
+	}
+	imageCount, annotationCount = cw.Count()
+	return imageCount, annotationCount, nil
+}
+
+// COCOWriter streams a COCO dataset. Images are written as they are added, while their
+// annotations are spooled to a temporary file because COCO lists them in a separate array;
+// Finish appends them and the categories. Close must be called to remove the spool.
+type COCOWriter struct {
+	w          *bufio.Writer
+	spool      *os.File
+	spoolBuf   *bufio.Writer
+	categories []*COCOCategory
+	byLabel    map[string]*COCOCategory
+	unlabeled  *COCOCategory
+	images     int
+	written    int
+}
+
+// spooledAnnotation keeps the keypoint names of an annotation until the category's
+// keypoint order is final.
+type spooledAnnotation struct {
+	COCOAnnotation
+	Names []string `json:"names,omitempty"`
+}
+
+// NewCOCOWriter starts a dataset on w with a category for each label.
+func NewCOCOWriter(w io.Writer, info COCOInfo, labels []*repository.Label) (*COCOWriter, error) {
+	cw := &COCOWriter{w: bufio.NewWriter(w), byLabel: make(map[string]*COCOCategory, len(labels))}
+
+	names := make(map[string]string, len(labels))
+	for _, l := range labels {
+		names[l.ID] = l.Name
+	}
+	for _, l := range labels {
+		id, err := strconv.ParseInt(l.ID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("label id %q: %w", l.ID, err)
+		}
+		c := &COCOCategory{ID: id, Name: l.Name, Supercategory: names[l.ParentID]}
+		cw.categories = append(cw.categories, c)
+		cw.byLabel[l.ID] = c
+	}
+
+	spool, err := os.CreateTemp("", "coco-annotations-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	cw.spool, cw.spoolBuf = spool, bufio.NewWriter(spool)
+
+	header, err := json.Marshal(info)
+	if err != nil {
+		cw.Close()
+		return nil, err
+	}
+	cw.w.WriteString(`{"info":`)
+	cw.w.Write(header)
+	cw.w.WriteString(`,"images":[`)
+	return cw, nil
+}
+
+// AddImage writes the image and spools its annotations. masks holds the pixels of the
+// mask annotations by annotation ID. Points and polylines have no COCO representation
+// and are skipped.
+func (cw *COCOWriter) AddImage(image *repository.Image, annotations []*repository.Annotation, masks map[string]*rle.RLE) error {
+	imageID, err := strconv.ParseInt(image.ID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("image id %q: %w", image.ID, err)
+	}
+
+	entry := COCOImage{ID: imageID, FileName: FileName(image), URL: image.URL}
+	if image.Metadata != nil {
+		entry.Width, entry.Height = image.Metadata.Width, image.Metadata.Height
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if cw.images > 0 {
+		cw.w.WriteByte(',')
+	}
+	cw.w.Write(b)
+	cw.images++
+
+	enc := json.NewEncoder(cw.spoolBuf)
+	for _, a := range annotations {
+		category := cw.category(a.LabelID)
+		spooled, ok, err := cocoAnnotation(a, imageID, category, masks[a.ID])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := enc.Encode(spooled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finish writes the spooled annotations and the categories and completes the document.
+func (cw *COCOWriter) Finish() error {
+	if err := cw.spoolBuf.Flush(); err != nil {
+		return err
+	}
+	if _, err := cw.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	cw.w.WriteString(`],"annotations":[`)
+	dec := json.NewDecoder(bufio.NewReader(cw.spool))
+	for {
+		var spooled spooledAnnotation
+		if err := dec.Decode(&spooled); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("read spooled annotation: %w", err)
+		}
+
+		a := spooled.COCOAnnotation
+		if len(spooled.Names) > 0 {
+			a.Keypoints, a.NumKeypoints = alignKeypoints(spooled.Names, spooled.Keypoints, cw.categoryByID(a.CategoryID).Keypoints)
+		}
+		b, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		if cw.written > 0 {
+			cw.w.WriteByte(',')
+		}
+		cw.w.Write(b)
+		cw.written++
+	}
+
+	categories := cw.categories
+	if cw.unlabeled != nil {
+		categories = append(categories, cw.unlabeled)
+	}
+	if categories == nil {
+		categories = []*COCOCategory{}
+	}
+	b, err := json.Marshal(categories)
+	if err != nil {
+		return err
+	}
+	cw.w.WriteString(`],"categories":`)
+	cw.w.Write(b)
+	cw.w.WriteString("}\n")
+	return cw.w.Flush()
+}
+
+// Count returns the number of images added and annotations written so far.
+func (cw *COCOWriter) Count() (images, annotations int) {
+	return cw.images, cw.written
+}
+
+// Close removes the spool. It does not complete the document; call Finish for that.
+func (cw *COCOWriter) Close() error {
+	if cw.spool == nil {
+		return nil
+	}
+	cw.spool.Close()
+	err := os.Remove(cw.spool.Name())
+	cw.spool = nil
+	return err
+}
+
+// category returns the category of the label, falling back to the unlabeled category
+// for annotations without a label or with one outside the exported taxonomy.
+func (cw *COCOWriter) category(labelID string) *COCOCategory {
+	if c, ok := cw.byLabel[labelID]; ok {
+		return c
+	}
+	if cw.unlabeled == nil {
+		cw.unlabeled = &COCOCategory{ID: COCOUnlabeledID, Name: Unlabeled}
+	}
+	return cw.unlabeled
+}
+
+func (cw *COCOWriter) categoryByID(id int64) *COCOCategory {
+	for _, c := range cw.categories {
+		if c.ID == id {
+			return c
+		}
+	}
+	return cw.unlabeled
+}
+
+// cocoAnnotation converts an annotation. Keypoints are kept in the annotation's own order
+// with their names, and the names are added to the category.
+func cocoAnnotation(a *repository.Annotation, imageID int64, category *COCOCategory, mask *rle.RLE) (spooledAnnotation, bool, error) {
+	id, err := strconv.ParseInt(a.ID, 10, 64)
+	if err != nil {
+		return spooledAnnotation{}, false, fmt.Errorf("annotation id %q: %w", a.ID, err)
+	}
+
+	out := spooledAnnotation{COCOAnnotation: COCOAnnotation{
+		ID:         id,
+		ImageID:    imageID,
+		CategoryID: category.ID,
+		BBox:       [4]float64{a.X, a.Y, a.Width, a.Height},
+		Area:       a.Width * a.Height,
+	}}
+
+	g := a.Geometry
+	if g == nil {
+		g = &geometry.Geometry{Type: geometry.Box}
+	}
+	switch g.Type {
+	case geometry.Box:
+		if g.Angle != 0 {
+			corners := geometry.Corners(a.X, a.Y, a.Width, a.Height, g.Angle)
+			polygon := make([]float64, 0, 8)
+			minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+			for _, c := range corners {
+				polygon = append(polygon, c[0], c[1])
+				minX, maxX = min(minX, c[0]), max(maxX, c[0])
+				minY, maxY = min(minY, c[1]), max(maxY, c[1])
+			}
+			out.Segmentation.Polygons = [][]float64{polygon}
+			out.BBox = [4]float64{minX, minY, maxX - minX, maxY - minY}
+		}
+	case geometry.Polygon:
+		polygon := make([]float64, 0, 2*len(g.Points))
+		for _, p := range g.Points {
+			polygon = append(polygon, p[0], p[1])
+		}
+		out.Segmentation.Polygons = [][]float64{polygon}
+		out.Area = polygonArea(g.Points)
+	case geometry.Mask:
+		if mask == nil {
+			return spooledAnnotation{}, false, fmt.Errorf("annotation %s: mask pixels missing", a.ID)
+		}
+		out.Segmentation.RLE = mask
+		out.Area = float64(g.Area)
+	case geometry.Keypoints:
+		for _, k := range g.Keypoints {
+			out.Names = append(out.Names, k.Name)
+			out.Keypoints = append(out.Keypoints, k.X, k.Y, float64(k.V))
+		}
+		addKeypointNames(category, out.Names)
+	default:
+		return spooledAnnotation{}, false, nil
+	}
+	return out, true, nil
+}
+
+func addKeypointNames(category *COCOCategory, names []string) {
+	for _, name := range names {
+		known := false
+		for _, k := range category.Keypoints {
+			if k == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			category.Keypoints = append(category.Keypoints, name)
+		}
+	}
+}
+
+// alignKeypoints reorders x, y, visibility triples given in the order of names into the
+// category's order, leaving keypoints the annotation does not have unlabeled.
+func alignKeypoints(names []string, triples []float64, order []string) ([]float64, int) {
+	index := make(map[string]int, len(order))
+	for i, name := range order {
+		index[name] = i
+	}
+
+	aligned := make([]float64, 3*len(order))
+	labeled := 0
+	for i, name := range names {
+		j := index[name]
+		copy(aligned[3*j:3*j+3], triples[3*i:3*i+3])
+		if triples[3*i+2] != geometry.NotLabeled {
+			labeled++
+		}
+	}
+	return aligned, labeled
+}
+
+// polygonArea returns the area enclosed by the polygon by the shoelace formula.
+func polygonArea(points []geometry.Vertex) float64 {
+	var sum float64
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		sum += p[0]*q[1] - q[0]*p[1]
+	}
+	return math.Abs(sum) / 2
+}
@@ -0,0 +1,316 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// PlanCOCOImport resolves a COCO dataset against the project without writing anything.
+// Categories match active labels by name or become new labels, nested under the label named
+// by their supercategory when there is one. Images match project images by URL, then by
+// the name they are exported under (see FileName); unmatched images with a URL are
+// registered as private images of the project, the rest are reported; a declared size
+// above maxPixels is a conflict. Annotations become keypoints if any keypoint is labeled,
+// else a mask for RLE segmentation, a polygon for each part of a polygon segmentation, or
+// a box. Annotations identical to one already on a matched image, e.g. when the same file
+// is imported twice, are conflicts.
+func PlanCOCOImport(
+	ctx context.Context,
+	coco *COCO,
+	project *repository.Project,
+	userID string,
+	maxPixels int,
+	images repository.Images,
+	annotations repository.Annotations,
+	labels repository.Labels,
+) (*ImportPlan, error) {
+	plan := newImportPlan()
+
+	categories, err := plan.categories(ctx, coco, project, labels)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := plan.images(ctx, coco, project, userID, maxPixels, images)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := loadExistingAnnotations(ctx, annotations, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	keypointNames := make(map[int64][]string, len(coco.Categories))
+	for _, c := range coco.Categories {
+		keypointNames[c.ID] = c.Keypoints
+	}
+
+	seen := make(map[int64]bool, len(coco.Annotations))
+	for _, a := range coco.Annotations {
+		item := fmt.Sprintf("annotations[id=%d]", a.ID)
+		if seen[a.ID] {
+			plan.conflict(item, "duplicate_id", "Annotation ID %d appears twice", a.ID)
+			continue
+		}
+		seen[a.ID] = true
+
+		target, ok := targets[a.ImageID]
+		if !ok {
+			plan.conflict(item, "unknown_image", "Image %d is not in the file", a.ImageID)
+			continue
+		}
+		if target == nil {
+			plan.Report.SkippedAnnotations++
+			continue
+		}
+		label, ok := categories[a.CategoryID]
+		if !ok {
+			plan.conflict(item, "unknown_category", "Category %d is not in the file", a.CategoryID)
+			continue
+		}
+
+		converted, err := cocoToAnnotations(a, keypointNames[a.CategoryID], target)
+		if err != nil {
+			plan.conflict(item, "invalid_annotation", "%s", err)
+			continue
+		}
+		for _, c := range converted {
+			if existing.has(target.image, label, c.Annotation) {
+				plan.conflict(item, "duplicate_annotation", "Image %s already has an identical annotation", target.image.ID)
+				continue
+			}
+			plan.addAnnotation(c, userID, target, label)
+		}
+	}
+	return plan, nil
+}
+
+// categories maps category IDs to labels, planning the labels to create.
+func (p *ImportPlan) categories(ctx context.Context, coco *COCO, project *repository.Project, labels repository.Labels) (map[int64]*repository.Label, error) {
+	set, err := loadLabelSet(ctx, labels, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*repository.Label, len(coco.Categories))
+	supercategories := make(map[*repository.Label]string)
+	for _, c := range coco.Categories {
+		item := fmt.Sprintf("categories[id=%d]", c.ID)
+		if _, ok := byID[c.ID]; ok {
+			p.conflict(item, "duplicate_id", "Category ID %d appears twice", c.ID)
+			continue
+		}
+		if c.Name == "" || len(c.Name) > 255 {
+			p.conflict(item, "invalid_name", "Category name must be between 1 and 255 characters")
+			continue
+		}
+
+		// categories sharing a name share a label
+		label, created := set.resolve(p, c.Name)
+		byID[c.ID] = label
+		if created != nil {
+			supercategories[label] = c.Supercategory
+		}
+	}
+
+	created := p.Batch.Labels
+	for _, imported := range created {
+		if parent, ok := set.byName[supercategories[imported.Label]]; ok && parent != imported.Label {
+			imported.Parent = parent
+		}
+	}
+	// supercategories can only loop through new labels, since existing ones are never reparented
+	for _, imported := range created {
+		for parent, steps := imported.Parent, 0; parent != nil; steps++ {
+			if parent == imported.Label || steps > len(created) {
+				p.conflict("categories", "label_cycle", "Category %q is its own supercategory", imported.Label.Name)
+				break
+			}
+			parent = parentOf(created, parent)
+		}
+	}
+	return byID, nil
+}
+
+func parentOf(created []*repository.ImportLabel, label *repository.Label) *repository.Label {
+	for _, imported := range created {
+		if imported.Label == label {
+			return imported.Parent
+		}
+	}
+	return nil
+}
+
+// images maps COCO image IDs to their target, or to nil for unmatched images.
+func (p *ImportPlan) images(ctx context.Context, coco *COCO, project *repository.Project, userID string, maxPixels int, images repository.Images) (map[int64]*importTarget, error) {
+	index, err := loadImageIndex(ctx, images, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[int64]*importTarget, len(coco.Images))
+	for _, c := range coco.Images {
+		item := fmt.Sprintf("images[id=%d]", c.ID)
+		if _, ok := targets[c.ID]; ok {
+			p.conflict(item, "duplicate_id", "Image ID %d appears twice", c.ID)
+			continue
+		}
+
+		image, ambiguous := index.match(c.URL, c.FileName)
+		if ambiguous > 0 {
+			p.conflict(item, "ambiguous_image", "%d project images are named %q", ambiguous, c.FileName)
+			targets[c.ID] = nil
+			continue
+		}
+		if image != nil {
+			p.Report.ImagesMatched++
+			targets[c.ID] = newImportTarget(image, c.Width, c.Height)
+			continue
+		}
+
+		if !registrable(c) {
+			p.unmatched(c.FileName)
+			targets[c.ID] = nil
+			continue
+		}
+		// registered images are never decoded here, so the declared size is all there is to check
+		if c.Height > 0 && c.Width > maxPixels/c.Height {
+			p.conflict(item, "too_many_pixels", "Image is %dx%d px, more than %d pixels", c.Width, c.Height, maxPixels)
+			targets[c.ID] = nil
+			continue
+		}
+		image = &repository.Image{
+			UserID:    userID,
+			ProjectID: project.ID,
+			URL:       c.URL,
+			Title:     c.FileName,
+		}
+		if c.Width > 0 && c.Height > 0 {
+			image.Metadata = &repository.ImageMetadata{Width: c.Width, Height: c.Height}
+		}
+		index.add(image)
+		p.Batch.Images = append(p.Batch.Images, image)
+		p.Report.ImagesCreated++
+		targets[c.ID] = newImportTarget(image, c.Width, c.Height)
+	}
+	return targets, nil
+}
+
+// registrable reports whether a COCO image can be registered as a new image by its URL.
+func registrable(c COCOImage) bool {
+	if c.FileName == "" || len(c.FileName) > 255 || len(c.URL) > 255 {
+		return false
+	}
+	u, err := url.Parse(c.URL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// cocoToAnnotations converts a COCO annotation into one or more annotations on the target.
+func cocoToAnnotations(a COCOAnnotation, names []string, target *importTarget) ([]*repository.ImportAnnotation, error) {
+	var out []*repository.ImportAnnotation
+	add := func(annotation *repository.Annotation, mask *rle.RLE) error {
+		if err := annotation.Geometry.Validate(); err != nil {
+			return err
+		}
+		if x, y, w, h, ok := annotation.Geometry.Bounds(); ok {
+			annotation.X, annotation.Y, annotation.Width, annotation.Height = x, y, w, h
+		}
+		if err := target.fit(annotation); err != nil {
+			return err
+		}
+		out = append(out, &repository.ImportAnnotation{Annotation: annotation, Mask: mask})
+		return nil
+	}
+
+	if keypoints, ok, err := cocoKeypoints(a.Keypoints, names); err != nil {
+		return nil, err
+	} else if ok {
+		g := &geometry.Geometry{Type: geometry.Keypoints, Keypoints: keypoints}
+		if err := add(&repository.Annotation{Geometry: g}, nil); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	if mask := a.Segmentation.RLE; mask != nil {
+		if target.width > 0 && (mask.Width != target.width || mask.Height != target.height) {
+			return nil, fmt.Errorf("mask is %dx%d px but the image is %dx%d px", mask.Width, mask.Height, target.width, target.height)
+		}
+		if rle.TooManyPixels(mask.Width, mask.Height) {
+			return nil, fmt.Errorf("mask exceeds %d pixels", rle.MaxPixels)
+		}
+		x, y, w, h, ok := mask.Bounds()
+		if !ok {
+			return nil, fmt.Errorf("mask has no foreground pixels")
+		}
+		annotation := &repository.Annotation{
+			X: float64(x), Y: float64(y), Width: float64(w), Height: float64(h),
+			Geometry: &geometry.Geometry{Type: geometry.Mask, Area: mask.Area()},
+		}
+		if err := add(annotation, mask); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	for i, polygon := range a.Segmentation.Polygons {
+		if len(polygon)%2 != 0 {
+			return nil, fmt.Errorf("polygon %d has an odd number of coordinates", i)
+		}
+		points := make([]geometry.Vertex, 0, len(polygon)/2)
+		for j := 0; j < len(polygon); j += 2 {
+			points = append(points, geometry.Vertex{polygon[j], polygon[j+1]})
+		}
+		if err := add(&repository.Annotation{Geometry: &geometry.Geometry{Type: geometry.Polygon, Points: points}}, nil); err != nil {
+			return nil, fmt.Errorf("polygon %d: %w", i, err)
+		}
+	}
+	if len(out) > 0 {
+		return out, nil
+	}
+
+	x, y, w, h := a.BBox[0], a.BBox[1], a.BBox[2], a.BBox[3]
+	if x < 0 || y < 0 || w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("bbox %v must have a non-negative origin and a positive size", a.BBox)
+	}
+	box := &repository.Annotation{X: x, Y: y, Width: w, Height: h, Geometry: &geometry.Geometry{Type: geometry.Box}}
+	if err := add(box, nil); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// cocoKeypoints reads x, y, visibility triples named after the category's keypoints. It
+// reports false when no keypoint is labeled, as in COCO's annotations without keypoints.
+func cocoKeypoints(triples []float64, names []string) ([]geometry.Keypoint, bool, error) {
+	if len(triples) == 0 {
+		return nil, false, nil
+	}
+	if len(triples)%3 != 0 {
+		return nil, false, fmt.Errorf("keypoints must be x, y, visibility triples")
+	}
+	if len(names) > 0 && len(triples) != 3*len(names) {
+		return nil, false, fmt.Errorf("%d keypoints given but the category has %d", len(triples)/3, len(names))
+	}
+
+	keypoints := make([]geometry.Keypoint, 0, len(triples)/3)
+	labeled := false
+	for i := 0; i < len(triples); i += 3 {
+		name := strconv.Itoa(i/3 + 1)
+		if len(names) > 0 {
+			name = names[i/3]
+		}
+		k := geometry.Keypoint{Name: name, V: int(triples[i+2])}
+		if k.V != geometry.NotLabeled {
+			k.X, k.Y = triples[i], triples[i+1]
+			labeled = true
+		}
+		keypoints = append(keypoints, k)
+	}
+	return keypoints, labeled, nil
+}
@@ -0,0 +1,144 @@
+package dataset
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// Unlabeled is the category exported for annotations without a label.
+const Unlabeled = "unlabeled"
+
+// FileName is the name an image is exported under: its title, which defaults to the
+// name of the uploaded file, or its ID for untitled images.
+func FileName(image *repository.Image) string {
+	if image.Title != "" {
+		return image.Title
+	}
+	return image.ID
+}
+
+// EachImage calls fn for every image of the project, in id order, with all of its
+// annotations. Images are read a page at a time so large projects are never held in
+// memory at once, and each page costs one query for its annotations. With withMasks the
+// pixels of mask annotations are read too, again once per page, and passed to fn by
+// annotation ID; otherwise masks is nil.
+func EachImage(
+	ctx context.Context,
+	images repository.Images,
+	annotations repository.Annotations,
+	projectID string,
+	withMasks bool,
+	fn func(image *repository.Image, annotations []*repository.Annotation, masks map[string]*rle.RLE) error,
+) error {
+	opts := repository.ListOptions{
+		Limit:   repository.MaxLimit,
+		Filters: map[string]string{"project_id": projectID},
+	}
+	for {
+		page, next, err := images.GetAll(ctx, opts)
+		if err != nil {
+			return err
+		}
+		if len(page) > 0 {
+			if err := eachPage(ctx, annotations, page, withMasks, fn); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		opts.Cursor = next
+	}
+}
+
+func eachPage(
+	ctx context.Context,
+	annotations repository.Annotations,
+	page []*repository.Image,
+	withMasks bool,
+	fn func(image *repository.Image, annotations []*repository.Annotation, masks map[string]*rle.RLE) error,
+) error {
+	ids := make([]string, len(page))
+	for i, image := range page {
+		ids[i] = image.ID
+	}
+	all, err := annotations.GetByImageIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byImage := make(map[string][]*repository.Annotation, len(page))
+	var maskIDs []string
+	for _, a := range all {
+		byImage[a.ImageID] = append(byImage[a.ImageID], a)
+		if a.Geometry != nil && a.Geometry.Type == geometry.Mask {
+			maskIDs = append(maskIDs, a.ID)
+		}
+	}
+
+	var masks map[string]*rle.RLE
+	if withMasks && len(maskIDs) > 0 {
+		if masks, err = annotations.GetMasks(ctx, maskIDs); err != nil {
+			return err
+		}
+	}
+	for _, image := range page {
+		if err := fn(image, byImage[image.ID], masks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImageAnnotations retrieves all annotations of the image in id order.
+func ImageAnnotations(ctx context.Context, annotations repository.Annotations, imageID string) ([]*repository.Annotation, error) {
+	var all []*repository.Annotation
+	opts := repository.ListOptions{Limit: repository.MaxLimit}
+	for {
+		page, next, err := annotations.GetByImageID(ctx, imageID, opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if next == "" {
+			return all, nil
+		}
+		opts.Cursor = next
+	}
+}
+
+// LabelNames maps the IDs of all the project's labels, archived ones included, to their names.
+func LabelNames(ctx context.Context, labels repository.Labels, projectID string) (map[string]string, error) {
+	list, err := labels.GetByProjectID(ctx, projectID, true)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(list))
+	for _, l := range list {
+		names[l.ID] = l.Name
+	}
+	return names, nil
+}
+
+// flagAttribute reads a flag attribute, such as difficult, as 0 or 1. Besides booleans it accepts numbers and
+// strings such as "1" or "true", as set by clients.
+func flagAttribute(v any) int {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+	case float64:
+		if v != 0 {
+			return 1
+		}
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil && b {
+			return 1
+		}
+	}
+	return 0
+}
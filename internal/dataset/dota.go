@@ -0,0 +1,62 @@
+package dataset
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// WriteDOTA writes the image's boxes in the DOTA v1 label format, one oriented box per line:
+//
+//	x1 y1 x2 y2 x3 y3 x4 y4 category difficult
+//
+// with the corners clockwise from the unrotated top-left, and difficult 1 for annotations
+// whose difficult attribute is set. labels maps label IDs to names; whitespace in names is
+// replaced since DOTA separates fields by spaces. Shapes other than boxes have no DOTA
+// representation and are skipped.
+func WriteDOTA(w io.Writer, annotations []*repository.Annotation, labels map[string]string) error {
+	bw := bufio.NewWriter(w)
+	for _, a := range annotations {
+		var angle float64
+		if a.Geometry != nil {
+			if a.Geometry.Type != geometry.Box {
+				continue
+			}
+			angle = a.Geometry.Angle
+		}
+
+		for _, c := range geometry.Corners(a.X, a.Y, a.Width, a.Height, angle) {
+			bw.WriteString(formatCoord(c[0]))
+			bw.WriteByte(' ')
+			bw.WriteString(formatCoord(c[1]))
+			bw.WriteByte(' ')
+		}
+		bw.WriteString(dotaCategory(labels[a.LabelID]))
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.Itoa(flagAttribute(a.Attributes["difficult"])))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// DOTAFileName is the name of the image's label file within a DOTA export.
+func DOTAFileName(image *repository.Image) string {
+	return "labelTxt/" + image.ID + ".txt"
+}
+
+func dotaCategory(name string) string {
+	if name == "" {
+		return Unlabeled
+	}
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// formatCoord writes coordinates with at most two decimals, as DOTA tools expect.
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
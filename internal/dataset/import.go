@@ -0,0 +1,261 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// importColor is the color of labels created by an import.
+const importColor = "#808080"
+
+// maxReportedConflicts bounds the conflicts listed in a report; all are counted.
+const maxReportedConflicts = 100
+
+// boundsTolerance lets imported shapes exceed the image by rounding error, in pixels.
+// Such shapes are clamped to the image, since the API accepts no overhang at all.
+const boundsTolerance = 1
+
+// ImportReport summarizes what an import does or would do. Conflicts are problems in the
+// file that prevent the import; unmatched images only cause their annotations to be skipped.
+type ImportReport struct {
+	LabelsMatched int `json:"labels_matched"`
+	LabelsCreated int `json:"labels_created"`
+	ImagesMatched int `json:"images_matched"`
+	ImagesCreated int `json:"images_created"`
+	Annotations   int `json:"annotations"`
+	// UnmatchedImages are the file names of images that are not in the project and
+	// cannot be registered
+	UnmatchedImages    []string         `json:"unmatched_images"`
+	SkippedAnnotations int              `json:"skipped_annotations"`
+	ConflictCount      int              `json:"conflict_count"`
+	Conflicts          []ImportConflict `json:"conflicts"`
+}
+
+// ImportConflict is a problem with one entry of the file. Item names the entry, e.g.
+// "annotations[id=12]", and Code is stable for clients to branch on.
+type ImportConflict struct {
+	Item    string `json:"item"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ImportPlan is a planned import: the batch to write and its report.
+type ImportPlan struct {
+	Batch  repository.ImportBatch
+	Report ImportReport
+}
+
+func newImportPlan() *ImportPlan {
+	plan := &ImportPlan{}
+	plan.Report.UnmatchedImages = []string{}
+	plan.Report.Conflicts = []ImportConflict{}
+	return plan
+}
+
+func (p *ImportPlan) conflict(item, code, format string, args ...any) {
+	p.Report.ConflictCount++
+	if len(p.Report.Conflicts) < maxReportedConflicts {
+		p.Report.Conflicts = append(p.Report.Conflicts, ImportConflict{Item: item, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// unmatched records an image of the file that has no counterpart in the project.
+func (p *ImportPlan) unmatched(fileName string) {
+	p.Report.UnmatchedImages = append(p.Report.UnmatchedImages, fileName)
+}
+
+// addAnnotation adds an annotation by the user to the batch.
+func (p *ImportPlan) addAnnotation(a *repository.ImportAnnotation, userID string, target *importTarget, label *repository.Label) {
+	a.Annotation.UserID = userID
+	a.Image = target.image
+	a.Label = label
+	p.Batch.Annotations = append(p.Batch.Annotations, a)
+	p.Report.Annotations++
+}
+
+// labelSet resolves label names against the project's active labels, planning a new
+// label for every name it does not know.
+type labelSet struct {
+	projectID string
+	byName    map[string]*repository.Label
+	existing  map[*repository.Label]bool
+	matched   map[*repository.Label]bool
+}
+
+func loadLabelSet(ctx context.Context, labels repository.Labels, projectID string) (*labelSet, error) {
+	list, err := labels.GetByProjectID(ctx, projectID, false)
+	if err != nil {
+		return nil, err
+	}
+	s := &labelSet{
+		projectID: projectID,
+		byName:    make(map[string]*repository.Label, len(list)),
+		existing:  make(map[*repository.Label]bool, len(list)),
+		matched:   make(map[*repository.Label]bool),
+	}
+	for _, l := range list {
+		s.byName[l.Name] = l
+		s.existing[l] = true
+	}
+	return s, nil
+}
+
+// resolve returns the label with the name. A label new to the project is added to the
+// plan and returned as created too, so that the caller can nest it.
+func (s *labelSet) resolve(p *ImportPlan, name string) (label *repository.Label, created *repository.ImportLabel) {
+	if l, ok := s.byName[name]; ok {
+		if s.existing[l] && !s.matched[l] {
+			s.matched[l] = true
+			p.Report.LabelsMatched++
+		}
+		return l, nil
+	}
+	l := &repository.Label{ProjectID: s.projectID, Name: name, Color: importColor}
+	s.byName[name] = l
+	created = &repository.ImportLabel{Label: l}
+	p.Batch.Labels = append(p.Batch.Labels, created)
+	p.Report.LabelsCreated++
+	return l, created
+}
+
+// imageIndex finds the project's images by URL and by the name they are exported under.
+type imageIndex struct {
+	byURL  map[string]*repository.Image
+	byName map[string][]*repository.Image
+}
+
+func loadImageIndex(ctx context.Context, images repository.Images, projectID string) (*imageIndex, error) {
+	x := &imageIndex{byURL: make(map[string]*repository.Image), byName: make(map[string][]*repository.Image)}
+	opts := repository.ListOptions{Limit: repository.MaxLimit, Filters: map[string]string{"project_id": projectID}}
+	for {
+		page, next, err := images.GetAll(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range page {
+			x.add(image)
+		}
+		if next == "" {
+			return x, nil
+		}
+		opts.Cursor = next
+	}
+}
+
+func (x *imageIndex) add(image *repository.Image) {
+	x.byURL[image.URL] = image
+	x.byName[FileName(image)] = append(x.byName[FileName(image)], image)
+}
+
+// match returns the image with the URL, or else the only image exported under the file
+// name. When several images share the name it returns their number instead.
+func (x *imageIndex) match(url, fileName string) (image *repository.Image, ambiguous int) {
+	if image, ok := x.byURL[url]; ok && url != "" {
+		return image, 0
+	}
+	switch matches := x.byName[fileName]; len(matches) {
+	case 0:
+		return nil, 0
+	case 1:
+		return matches[0], 0
+	default:
+		return nil, len(matches)
+	}
+}
+
+// existingAnnotations holds the annotations already on the matched images, by annotationKey.
+type existingAnnotations map[string]bool
+
+// loadExistingAnnotations reads the annotations of the images the targets matched, in one query.
+func loadExistingAnnotations(ctx context.Context, annotations repository.Annotations, targets map[int64]*importTarget) (existingAnnotations, error) {
+	var ids []string
+	for _, t := range targets {
+		if t != nil && t.image.ID != "" {
+			ids = append(ids, t.image.ID)
+		}
+	}
+	existing := make(existingAnnotations)
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	list, err := annotations.GetByImageIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range list {
+		existing[annotationKey(a.ImageID, a.LabelID, a)] = true
+	}
+	return existing, nil
+}
+
+// has reports whether the image already has an annotation with the label, type and bounding
+// box. Images and labels new to the project have none.
+func (e existingAnnotations) has(image *repository.Image, label *repository.Label, a *repository.Annotation) bool {
+	if image.ID == "" || label.ID == "" {
+		return false
+	}
+	return e[annotationKey(image.ID, label.ID, a)]
+}
+
+// annotationKey identifies an annotation by image, label, type and bounding box rounded to
+// hundredths of a pixel, which survives an export and import round trip.
+func annotationKey(imageID, labelID string, a *repository.Annotation) string {
+	shape := geometry.Box
+	if a.Geometry != nil {
+		shape = a.Geometry.Type
+	}
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	return fmt.Sprintf("%s|%s|%s|%g|%g|%g|%g", imageID, labelID, shape, round(a.X), round(a.Y), round(a.Width), round(a.Height))
+}
+
+// importTarget is the image a file's image resolved to, with the size shapes are
+// checked against: the stored dimensions, or else the ones given in the file.
+type importTarget struct {
+	image         *repository.Image
+	width, height int
+}
+
+func newImportTarget(image *repository.Image, width, height int) *importTarget {
+	if image.Metadata != nil && image.Metadata.Width > 0 && image.Metadata.Height > 0 {
+		width, height = image.Metadata.Width, image.Metadata.Height
+	}
+	return &importTarget{image: image, width: width, height: height}
+}
+
+// fit clamps a shape reaching outside the image by at most boundsTolerance to its edges,
+// and rejects one reaching further, when the image size is known.
+func (t *importTarget) fit(annotation *repository.Annotation) error {
+	if t.width == 0 || t.height == 0 {
+		return nil
+	}
+	width, height := float64(t.width), float64(t.height)
+	if annotation.X+annotation.Width > width+boundsTolerance ||
+		annotation.Y+annotation.Height > height+boundsTolerance {
+		return fmt.Errorf("shape exceeds the %dx%d px image", t.width, t.height)
+	}
+
+	if g := annotation.Geometry; g != nil {
+		for i, p := range g.Points {
+			g.Points[i] = geometry.Vertex{min(p[0], width), min(p[1], height)}
+		}
+		for i, k := range g.Keypoints {
+			if k.V != geometry.NotLabeled {
+				g.Keypoints[i].X, g.Keypoints[i].Y = min(k.X, width), min(k.Y, height)
+			}
+		}
+		if x, y, w, h, ok := g.Bounds(); ok {
+			annotation.X, annotation.Y, annotation.Width, annotation.Height = x, y, w, h
+			return nil
+		}
+	}
+	if annotation.X >= width || annotation.Y >= height {
+		return fmt.Errorf("shape exceeds the %dx%d px image", t.width, t.height)
+	}
+	annotation.Width = min(annotation.Width, width-annotation.X)
+	annotation.Height = min(annotation.Height, height-annotation.Y)
+	return nil
+}
@@ -0,0 +1,154 @@
+package dataset
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// VOCUnspecifiedPose is the pose of objects whose pose was not annotated.
+const VOCUnspecifiedPose = "Unspecified"
+
+// VOC is the annotation file of one image in the Pascal VOC format. Box corners are whole
+// pixels counted from 1, both inclusive, so a box at x with width w spans x+1 to x+w.
+type VOC struct {
+	XMLName   xml.Name    `xml:"annotation"`
+	Filename  string      `xml:"filename"`
+	Size      VOCSize     `xml:"size"`
+	Segmented int         `xml:"segmented"`
+	Objects   []VOCObject `xml:"object"`
+}
+
+// VOCSize is the image size; zero when it is unknown.
+type VOCSize struct {
+	Width  int `xml:"width"`
+	Height int `xml:"height"`
+	Depth  int `xml:"depth"`
+}
+
+// VOCObject is an object instance. Difficult marks objects excluded from evaluation and
+// Truncated those extending beyond the image; both are 0 or 1.
+type VOCObject struct {
+	Name      string `xml:"name"`
+	Pose      string `xml:"pose"`
+	Truncated int    `xml:"truncated"`
+	Difficult int    `xml:"difficult"`
+	Occluded  *int   `xml:"occluded,omitempty"`
+	BndBox    VOCBox `xml:"bndbox"`
+}
+
+type VOCBox struct {
+	XMin float64 `xml:"xmin"`
+	YMin float64 `xml:"ymin"`
+	XMax float64 `xml:"xmax"`
+	YMax float64 `xml:"ymax"`
+}
+
+// WriteVOC writes the image's annotations as a Pascal VOC annotation file. Boxes, polygons
+// and masks become objects by their bounding box, rotated boxes by the box around their
+// corners; points, polylines and keypoints have no VOC representation and are skipped.
+// labels maps label IDs to names, and the pose, truncated, difficult and occluded fields
+// come from the annotation's attributes.
+func WriteVOC(w io.Writer, image *repository.Image, annotations []*repository.Annotation, labels map[string]string) error {
+	voc := VOC{Filename: FileName(image), Objects: []VOCObject{}}
+	if m := image.Metadata; m != nil {
+		voc.Size = VOCSize{Width: m.Width, Height: m.Height, Depth: vocDepth(m.ColorModel)}
+	}
+
+	for _, a := range annotations {
+		x, y, width, height, ok := vocBounds(a)
+		if !ok {
+			continue
+		}
+		name := labels[a.LabelID]
+		if name == "" {
+			name = Unlabeled
+		}
+		object := VOCObject{
+			Name:      name,
+			Pose:      VOCUnspecifiedPose,
+			Truncated: flagAttribute(a.Attributes["truncated"]),
+			Difficult: flagAttribute(a.Attributes["difficult"]),
+			BndBox:    vocBox(x, y, width, height, voc.Size),
+		}
+		if pose, ok := a.Attributes["pose"].(string); ok && pose != "" {
+			object.Pose = pose
+		}
+		if occluded, ok := a.Attributes["occluded"]; ok {
+			flag := flagAttribute(occluded)
+			object.Occluded = &flag
+		}
+		voc.Objects = append(voc.Objects, object)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(voc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// VOCFileName is the name of the image's annotation file within a VOC export.
+func VOCFileName(image *repository.Image) string {
+	return "Annotations/" + image.ID + ".xml"
+}
+
+// vocBounds returns the rectangle exported for the annotation, reporting false for shapes
+// VOC cannot hold.
+func vocBounds(a *repository.Annotation) (x, y, width, height float64, ok bool) {
+	if a.Geometry == nil {
+		return a.X, a.Y, a.Width, a.Height, true
+	}
+	switch a.Geometry.Type {
+	case geometry.Box:
+		if a.Geometry.Angle == 0 {
+			return a.X, a.Y, a.Width, a.Height, true
+		}
+		corners := geometry.Corners(a.X, a.Y, a.Width, a.Height, a.Geometry.Angle)
+		g := geometry.Geometry{Points: corners[:]}
+		return g.Bounds()
+	case geometry.Polygon, geometry.Mask:
+		return a.X, a.Y, a.Width, a.Height, true
+	default:
+		return 0, 0, 0, 0, false
+	}
+}
+
+// vocBox rounds the rectangle to VOC's inclusive 1-based corners, kept within the image
+// when its size is known.
+func vocBox(x, y, width, height float64, size VOCSize) VOCBox {
+	b := VOCBox{
+		XMin: max(math.Round(x), 0) + 1,
+		YMin: max(math.Round(y), 0) + 1,
+		XMax: math.Round(x + width),
+		YMax: math.Round(y + height),
+	}
+	if size.Width > 0 && size.Height > 0 {
+		b.XMax = min(b.XMax, float64(size.Width))
+		b.YMax = min(b.YMax, float64(size.Height))
+	}
+	// a box thinner than a pixel still covers the pixel it is in
+	b.XMax = max(b.XMax, b.XMin)
+	b.YMax = max(b.YMax, b.YMin)
+	return b
+}
+
+// vocDepth is the number of color channels VOC records for the color model.
+func vocDepth(colorModel string) int {
+	switch colorModel {
+	case "":
+		return 0
+	case "gray", "gray16":
+		return 1
+	default:
+		return 3
+	}
+}
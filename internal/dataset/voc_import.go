@@ -0,0 +1,165 @@
+package dataset
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// maxVOCFileSize and maxVOCArchiveSize bound a single annotation file and all of them
+// once decompressed, so a small archive cannot expand without limit.
+const (
+	maxVOCFileSize    = 4 << 20
+	maxVOCArchiveSize = 1 << 30
+)
+
+// PlanVOCImport resolves a zip of Pascal VOC annotation files against the project without
+// writing anything. Every .xml file in the archive is read, whatever its directory. Images
+// match project images by the name they are exported under (see FileName); VOC files have
+// no URL to register an image with, so the rest are reported as unmatched. Objects match
+// active labels by name or become new labels, and each becomes a box annotation keeping
+// difficult and truncated, and pose and occluded when given, as attributes.
+func PlanVOCImport(
+	ctx context.Context,
+	archive *zip.Reader,
+	project *repository.Project,
+	userID string,
+	images repository.Images,
+	labels repository.Labels,
+) (*ImportPlan, error) {
+	plan := newImportPlan()
+
+	set, err := loadLabelSet(ctx, labels, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	index, err := loadImageIndex(ctx, images, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]string)
+	var total uint64
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".xml") || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		// the zip reader fails entries longer than their header claims
+		if total += f.UncompressedSize64; total > maxVOCArchiveSize {
+			plan.conflict(f.Name, "archive_too_large", "Annotation files exceed %d bytes in total", maxVOCArchiveSize)
+			break
+		}
+
+		voc, err := readVOC(f)
+		if err != nil {
+			plan.conflict(f.Name, "invalid_file", "%s", err)
+			continue
+		}
+
+		if other, ok := seen[voc.Filename]; ok {
+			plan.conflict(f.Name, "duplicate_image", "Image %q is also annotated by %s", voc.Filename, other)
+			continue
+		}
+		seen[voc.Filename] = f.Name
+
+		image, ambiguous := index.match("", voc.Filename)
+		if ambiguous > 0 {
+			plan.conflict(f.Name, "ambiguous_image", "%d project images are named %q", ambiguous, voc.Filename)
+			continue
+		}
+		if image == nil {
+			plan.unmatched(voc.Filename)
+			plan.Report.SkippedAnnotations += len(voc.Objects)
+			continue
+		}
+		plan.Report.ImagesMatched++
+		target := newImportTarget(image, voc.Size.Width, voc.Size.Height)
+
+		for i, o := range voc.Objects {
+			item := fmt.Sprintf("%s/object[%d]", f.Name, i)
+			if o.Name == "" || len(o.Name) > 255 {
+				plan.conflict(item, "invalid_name", "Object name must be between 1 and 255 characters")
+				continue
+			}
+			annotation, err := vocToAnnotation(o, target)
+			if err != nil {
+				plan.conflict(item, "invalid_annotation", "%s", err)
+				continue
+			}
+			label, _ := set.resolve(plan, o.Name)
+			plan.addAnnotation(&repository.ImportAnnotation{Annotation: annotation}, userID, target, label)
+		}
+	}
+	return plan, nil
+}
+
+// readVOC decodes an annotation file of the archive.
+func readVOC(f *zip.File) (*VOC, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxVOCFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxVOCFileSize {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxVOCFileSize)
+	}
+
+	var voc VOC
+	if err := xml.Unmarshal(data, &voc); err != nil {
+		return nil, fmt.Errorf("invalid VOC XML: %w", err)
+	}
+	if voc.Filename == "" {
+		return nil, fmt.Errorf("filename is missing")
+	}
+	return &voc, nil
+}
+
+// vocToAnnotation converts an object into a box on the target. Corners at 0, as written
+// by tools counting from 0, are clamped to the image edge.
+func vocToAnnotation(o VOCObject, target *importTarget) (*repository.Annotation, error) {
+	b := o.BndBox
+	for _, v := range [4]float64{b.XMin, b.YMin, b.XMax, b.YMax} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("bndbox has a non-finite corner")
+		}
+	}
+	x, y := max(b.XMin-1, 0), max(b.YMin-1, 0)
+	annotation := &repository.Annotation{
+		X:        x,
+		Y:        y,
+		Width:    b.XMax - x,
+		Height:   b.YMax - y,
+		Geometry: &geometry.Geometry{Type: geometry.Box},
+		Attributes: map[string]any{
+			"difficult": o.Difficult != 0,
+			"truncated": o.Truncated != 0,
+		},
+	}
+	if annotation.Width <= 0 || annotation.Height <= 0 {
+		return nil, fmt.Errorf("bndbox (%g, %g)-(%g, %g) must have xmax >= xmin and ymax >= ymin", b.XMin, b.YMin, b.XMax, b.YMax)
+	}
+	if err := target.fit(annotation); err != nil {
+		return nil, err
+	}
+
+	if o.Pose != "" && o.Pose != VOCUnspecifiedPose {
+		annotation.Attributes["pose"] = o.Pose
+	}
+	if o.Occluded != nil {
+		annotation.Attributes["occluded"] = *o.Occluded != 0
+	}
+	return annotation, nil
+}
@@ -2,14 +2,31 @@ package response
 
 import (
 	"fmt"
-	"strings"
+	"net/http"
 
+	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 )
 
+// Response is the envelope shared by every API response.
 type Response struct {
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
+	Status string     `json:"status"`
+	Error  *ErrorBody `json:"error,omitempty"`
+}
+
+// ErrorBody describes a failed request. Code is stable and meant for clients to branch on;
+// Message is human readable and may change.
+type ErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 const (
@@ -17,38 +34,119 @@ const (
 	StatusError = "Error"
 )
 
+// Error codes returned in ErrorBody.Code.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeValidation       = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethod           = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeTooLarge         = "payload_too_large"
+	CodeUnsupportedMedia = "unsupported_media_type"
+	CodeInternal         = "internal_error"
+	CodeUnavailable      = "unavailable"
+)
+
+var httpStatuses = map[string]int{
+	CodeBadRequest:       http.StatusBadRequest,
+	CodeValidation:       http.StatusUnprocessableEntity,
+	CodeUnauthorized:     http.StatusUnauthorized,
+	CodeForbidden:        http.StatusForbidden,
+	CodeNotFound:         http.StatusNotFound,
+	CodeMethod:           http.StatusMethodNotAllowed,
+	CodeConflict:         http.StatusConflict,
+	CodeTooLarge:         http.StatusRequestEntityTooLarge,
+	CodeUnsupportedMedia: http.StatusUnsupportedMediaType,
+	CodeInternal:         http.StatusInternalServerError,
+	CodeUnavailable:      http.StatusServiceUnavailable,
+}
+
 func OK() Response {
 	return Response{
 		Status: StatusOK,
 	}
 }
 
-func Error(msg string) Response {
+// Error builds an error response with the given code and message.
+func Error(code, msg string) Response {
 	return Response{
 		Status: StatusError,
-		Error:  msg,
+		Error: &ErrorBody{
+			Code:    code,
+			Message: msg,
+		},
+	}
+}
+
+func BadRequest(msg string) Response   { return Error(CodeBadRequest, msg) }
+func Unauthorized(msg string) Response { return Error(CodeUnauthorized, msg) }
+func Forbidden(msg string) Response    { return Error(CodeForbidden, msg) }
+func NotFound(msg string) Response     { return Error(CodeNotFound, msg) }
+func Conflict(msg string) Response     { return Error(CodeConflict, msg) }
+func Internal(msg string) Response     { return Error(CodeInternal, msg) }
+
+// HTTPStatus returns the HTTP status code an error code is sent with.
+func HTTPStatus(code string) int {
+	if status, ok := httpStatuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// RenderError writes an error response with the HTTP status matching its code.
+func RenderError(w http.ResponseWriter, r *http.Request, res Response) {
+	status := http.StatusInternalServerError
+	if res.Error != nil {
+		status = HTTPStatus(res.Error.Code)
 	}
+	render.Status(r, status)
+	render.JSON(w, r, res)
 }
 
 func ValidationError(errs validator.ValidationErrors) Response {
-	var errMsg []string
+	details := make([]FieldError, 0, len(errs))
 
 	for _, err := range errs {
+		var msg string
 		switch err.ActualTag() {
 		case "required":
-			errMsg = append(errMsg, fmt.Sprintf("Field '%s' is required", err.Field()))
+			msg = fmt.Sprintf("Field '%s' is required", err.Field())
 		case "email":
-			errMsg = append(errMsg, fmt.Sprintf("Field '%s' must be a valid email", err.Field()))
+			msg = fmt.Sprintf("Field '%s' must be a valid email", err.Field())
 		case "min":
-			errMsg = append(errMsg, fmt.Sprintf("Field '%s' must be at least %s characters", err.Field(), err.Param()))
+			msg = fmt.Sprintf("Field '%s' must be at least %s characters", err.Field(), err.Param())
 		case "max":
-			errMsg = append(errMsg, fmt.Sprintf("Field '%s' must be at most %s characters", err.Field(), err.Param()))
+			msg = fmt.Sprintf("Field '%s' must be at most %s characters", err.Field(), err.Param())
+		case "gte":
+			msg = fmt.Sprintf("Field '%s' must be greater than or equal to %s", err.Field(), err.Param())
+		case "gt":
+			msg = fmt.Sprintf("Field '%s' must be greater than %s", err.Field(), err.Param())
+		case "oneof":
+			msg = fmt.Sprintf("Field '%s' must be one of [%s]", err.Field(), err.Param())
+		case "url":
+			msg = fmt.Sprintf("Field '%s' must be a valid URL", err.Field())
+		case "numeric":
+			msg = fmt.Sprintf("Field '%s' must be numeric", err.Field())
+		case "hexcolor":
+			msg = fmt.Sprintf("Field '%s' must be a hex color such as #ff8800", err.Field())
 		default:
-			errMsg = append(errMsg, fmt.Sprintf("Field '%s' is invalid", err.Field()))
+			msg = fmt.Sprintf("Field '%s' is invalid", err.Field())
 		}
+		details = append(details, FieldError{
+			Field:   err.Field(),
+			Code:    err.ActualTag(),
+			Message: msg,
+		})
 	}
-	return Response{
-		Status: StatusError,
-		Error:  strings.Join(errMsg, ", "),
-	}
+
+	return Invalid(details...)
+}
+
+// Invalid builds a validation error from field checks done outside the validator.
+func Invalid(details ...FieldError) Response {
+	res := Error(CodeValidation, "Request validation failed")
+	res.Error.Details = details
+	return res
 }
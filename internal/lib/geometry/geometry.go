@@ -0,0 +1,203 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Geometry types, stored as the "type" discriminator.
+const (
+	Box       = "box"
+	Polygon   = "polygon"
+	Polyline  = "polyline"
+	Point     = "point"
+	Keypoints = "keypoints"
+	Mask      = "mask"
+)
+
+// MaxPoints bounds the vertices or keypoints of a single geometry.
+const MaxPoints = 10000
+
+// Keypoint visibility flags, as in COCO.
+const (
+	NotLabeled = 0
+	Occluded   = 1
+	Visible    = 2
+)
+
+// Geometry is the shape of an annotation. Boxes keep their rectangle in the
+// annotation's x, y, width and height; every other type carries its points here.
+type Geometry struct {
+	Type string `json:"type"`
+	// Angle rotates a box clockwise about its center, in degrees within [-180, 180].
+	// The rectangle is given unrotated and the angle always applies in pixel space.
+	Angle float64 `json:"angle,omitempty"`
+	// Points are the vertices of a polygon or polyline, or the single point of a point
+	Points []Vertex `json:"points,omitempty"`
+	// Keypoints are the named landmarks of a keypoints geometry, e.g. a pose skeleton
+	Keypoints []Keypoint `json:"keypoints,omitempty"`
+	// Area is the pixel count of a mask, whose pixels are stored apart from the geometry
+	Area int `json:"area,omitempty"`
+}
+
+// Vertex is a point in image pixels, encoded as [x, y]. Coordinates are sub-pixel.
+type Vertex [2]float64
+
+// Keypoint is a named landmark. V is its visibility; unlabeled keypoints have no position.
+type Keypoint struct {
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	V    int     `json:"v"`
+}
+
+// Validate checks that the geometry is well formed for its type.
+func (g *Geometry) Validate() error {
+	switch g.Type {
+	case Box:
+		if len(g.Points) > 0 || len(g.Keypoints) > 0 {
+			return errors.New("a box has no points; use x, y, width and height")
+		}
+		if math.IsNaN(g.Angle) || g.Angle < -180 || g.Angle > 180 {
+			return errors.New("angle must be between -180 and 180 degrees")
+		}
+		return nil
+	case Polygon:
+		return g.validatePoints(3, MaxPoints)
+	case Polyline:
+		return g.validatePoints(2, MaxPoints)
+	case Point:
+		return g.validatePoints(1, 1)
+	case Keypoints:
+		return g.validateKeypoints()
+	case Mask:
+		if len(g.Points) > 0 || len(g.Keypoints) > 0 || g.Angle != 0 {
+			return errors.New("a mask has no points; upload its pixels instead")
+		}
+		return nil
+	case "":
+		return errors.New("type is required")
+	default:
+		return fmt.Errorf("unknown type %q", g.Type)
+	}
+}
+
+func (g *Geometry) validatePoints(min, max int) error {
+	if g.Angle != 0 {
+		return fmt.Errorf("a %s cannot be rotated", g.Type)
+	}
+	if len(g.Keypoints) > 0 {
+		return fmt.Errorf("a %s has no keypoints", g.Type)
+	}
+	if len(g.Points) < min || len(g.Points) > max {
+		if min == max {
+			return fmt.Errorf("a %s needs exactly %d point", g.Type, min)
+		}
+		return fmt.Errorf("a %s needs between %d and %d points", g.Type, min, max)
+	}
+	for i, p := range g.Points {
+		if p[0] < 0 || p[1] < 0 {
+			return fmt.Errorf("point %d has negative coordinates", i)
+		}
+	}
+	return nil
+}
+
+func (g *Geometry) validateKeypoints() error {
+	if g.Angle != 0 {
+		return errors.New("keypoints cannot be rotated")
+	}
+	if len(g.Points) > 0 {
+		return errors.New("keypoints are given in keypoints, not points")
+	}
+	if len(g.Keypoints) == 0 || len(g.Keypoints) > MaxPoints {
+		return fmt.Errorf("keypoints need between 1 and %d entries", MaxPoints)
+	}
+
+	seen := make(map[string]bool, len(g.Keypoints))
+	labeled := false
+	for i, k := range g.Keypoints {
+		if k.Name == "" {
+			return fmt.Errorf("keypoint %d has no name", i)
+		}
+		if seen[k.Name] {
+			return fmt.Errorf("keypoint %q appears twice", k.Name)
+		}
+		seen[k.Name] = true
+
+		switch k.V {
+		case NotLabeled:
+			if k.X != 0 || k.Y != 0 {
+				return fmt.Errorf("unlabeled keypoint %q has a position", k.Name)
+			}
+		case Occluded, Visible:
+			if k.X < 0 || k.Y < 0 {
+				return fmt.Errorf("keypoint %q has negative coordinates", k.Name)
+			}
+			labeled = true
+		default:
+			return fmt.Errorf("keypoint %q has visibility %d, want 0, 1 or 2", k.Name, k.V)
+		}
+	}
+	if !labeled {
+		return errors.New("at least one keypoint must be labeled")
+	}
+	return nil
+}
+
+// Bounds returns the smallest axis-aligned rectangle holding the points or labeled
+// keypoints. It reports false for boxes and masks, whose rectangle is stored separately.
+func (g *Geometry) Bounds() (x, y, width, height float64, ok bool) {
+	var xs, ys []float64
+	for _, p := range g.Points {
+		xs = append(xs, p[0])
+		ys = append(ys, p[1])
+	}
+	for _, k := range g.Keypoints {
+		if k.V != NotLabeled {
+			xs = append(xs, k.X)
+			ys = append(ys, k.Y)
+		}
+	}
+	if len(xs) == 0 {
+		return 0, 0, 0, 0, false
+	}
+
+	minX, maxX := xs[0], xs[0]
+	minY, maxY := ys[0], ys[0]
+	for i := range xs {
+		minX, maxX = min(minX, xs[i]), max(maxX, xs[i])
+		minY, maxY = min(minY, ys[i]), max(maxY, ys[i])
+	}
+	return minX, minY, maxX - minX, maxY - minY, true
+}
+
+// Scale multiplies the x coordinates by sx and the y coordinates by sy, e.g. to convert
+// between pixels and coordinates normalized to the image size.
+func (g *Geometry) Scale(sx, sy float64) {
+	for i := range g.Points {
+		g.Points[i][0] *= sx
+		g.Points[i][1] *= sy
+	}
+	for i := range g.Keypoints {
+		g.Keypoints[i].X *= sx
+		g.Keypoints[i].Y *= sy
+	}
+}
+
+// Corners returns the four corners of the rectangle at (x, y) of the given size rotated
+// clockwise by angle degrees about its center, starting from the unrotated top-left
+// corner and going clockwise.
+func Corners(x, y, width, height, angle float64) [4]Vertex {
+	cx, cy := x+width/2, y+height/2
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+
+	var corners [4]Vertex
+	for i, c := range [4]Vertex{{x, y}, {x + width, y}, {x + width, y + height}, {x, y + height}} {
+		dx, dy := c[0]-cx, c[1]-cy
+		// with y pointing down this turns clockwise on screen
+		corners[i] = Vertex{cx + dx*cos - dy*sin, cy + dx*sin + dy*cos}
+	}
+	return corners
+}
@@ -0,0 +1,76 @@
+package imagemeta
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a fetched URL, or a redirect it leads to, resolves
+// to an address on the server's own network.
+var ErrForbiddenAddress = errors.New("address not allowed")
+
+// maxRedirects matches the default of net/http.
+const maxRedirects = 10
+
+// NewClient returns a client for Fetch that only connects to public addresses, so that
+// registering an image URL cannot be used to probe the server's network. The address is
+// checked after DNS resolution, when dialing, which covers every redirect and rules out
+// a name resolving differently between the check and the connection.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+			}
+			if !publicAddr(addr.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr.Addr())
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// a proxy would dial the target itself, out of reach of the check
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     time.Minute,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("stopped after 10 redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported URL scheme %q", req.URL.Scheme)
+			}
+			// literal addresses are refused before any connection is attempted
+			if addr, err := netip.ParseAddr(req.URL.Hostname()); err == nil && !publicAddr(addr) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+			}
+			return nil
+		},
+	}
+}
+
+// publicAddr reports whether addr may be fetched: not loopback, private, link-local,
+// multicast or unspecified.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
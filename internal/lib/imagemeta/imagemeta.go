@@ -0,0 +1,194 @@
+package imagemeta
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/phash"
+	"github.com/rwcarlsen/goexif/exif"
+	_ "golang.org/x/image/webp"
+)
+
+// ErrUnsupported is returned when the data is not an image in a supported format.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// ErrTooLarge is returned by Fetch when the remote image exceeds the size limit.
+var ErrTooLarge = errors.New("image too large")
+
+// ErrTooManyPixels is returned when the image header declares more pixels than allowed.
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+// Metadata is what is known about an image file after decoding its header.
+type Metadata struct {
+	Width      int
+	Height     int
+	MimeType   string
+	Size       int64
+	ColorModel string
+	// The remaining fields come from EXIF and are zero when absent
+	CapturedAt  *time.Time
+	CameraMake  string
+	CameraModel string
+	Orientation int
+	// PerceptualHash fingerprints the pixels for near-duplicate detection
+	PerceptualHash phash.Hash
+}
+
+var mimeTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+}
+
+// Extract decodes the image and EXIF block of r, which holds size bytes. The header is
+// decoded first and images of more than maxPixels are refused with ErrTooManyPixels, since
+// the full decode allocates every pixel and a small file can declare huge dimensions.
+func Extract(r io.ReadSeeker, size int64, maxPixels int) (*Metadata, error) {
+	const op = "imagemeta.Extract"
+
+	cfg, format, err := image.DecodeConfig(r)
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// divide rather than multiply so that the check cannot overflow
+	if cfg.Height > 0 && cfg.Width > maxPixels/cfg.Height {
+		return nil, ErrTooManyPixels
+	}
+
+	meta := &Metadata{
+		Width:      cfg.Width,
+		Height:     cfg.Height,
+		MimeType:   mimeTypes[format],
+		Size:       size,
+		ColorModel: colorModelName(cfg.ColorModel),
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	meta.PerceptualHash = phash.Compute(img)
+
+	if format == "jpeg" {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		// a missing or malformed EXIF block is common and not an error
+		if x, err := exif.Decode(r); err == nil {
+			readExif(x, meta)
+		}
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return meta, nil
+}
+
+// Fetch downloads the image at url, up to maxSize bytes, and extracts its metadata.
+func Fetch(ctx context.Context, client *http.Client, url string, maxSize int64, maxPixels int) (*Metadata, error) {
+	const op = "imagemeta.Fetch"
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("%s: unsupported URL scheme", op)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status %s", op, res.Status)
+	}
+	if res.ContentLength > maxSize {
+		return nil, ErrTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrTooLarge
+	}
+
+	return Extract(bytes.NewReader(data), int64(len(data)), maxPixels)
+}
+
+func readExif(x *exif.Exif, meta *Metadata) {
+	if t, err := x.DateTime(); err == nil {
+		meta.CapturedAt = &t
+	}
+	if tag, err := x.Get(exif.Make); err == nil {
+		if s, err := tag.StringVal(); err == nil {
+			meta.CameraMake = strings.TrimSpace(s)
+		}
+	}
+	if tag, err := x.Get(exif.Model); err == nil {
+		if s, err := tag.StringVal(); err == nil {
+			meta.CameraModel = strings.TrimSpace(s)
+		}
+	}
+	if tag, err := x.Get(exif.Orientation); err == nil {
+		// valid orientations are 1-8, anything else is ignored
+		if v, err := tag.Int(0); err == nil && v >= 1 && v <= 8 {
+			meta.Orientation = v
+		}
+	}
+}
+
+func colorModelName(m color.Model) string {
+	if _, ok := m.(color.Palette); ok {
+		return "paletted"
+	}
+	switch m {
+	case color.RGBAModel:
+		return "rgba"
+	case color.RGBA64Model:
+		return "rgba64"
+	case color.NRGBAModel:
+		return "nrgba"
+	case color.NRGBA64Model:
+		return "nrgba64"
+	case color.AlphaModel:
+		return "alpha"
+	case color.Alpha16Model:
+		return "alpha16"
+	case color.GrayModel:
+		return "gray"
+	case color.Gray16Model:
+		return "gray16"
+	case color.YCbCrModel:
+		return "ycbcr"
+	case color.NYCbCrAModel:
+		return "nycbcra"
+	case color.CMYKModel:
+		return "cmyk"
+	default:
+		return "unknown"
+	}
+}
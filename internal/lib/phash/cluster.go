@@ -0,0 +1,112 @@
+package phash
+
+// Item is a hashed image taking part in clustering.
+type Item struct {
+	ID   string
+	Hash Hash
+}
+
+// Cluster groups items whose hashes are within threshold bits of each other,
+// transitively: if a is close to b and b to c, all three share a cluster.
+// Only clusters with at least two items are returned, each in input order.
+func Cluster(items []Item, threshold int) [][]Item {
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	tree := &bkTree{}
+	for i, item := range items {
+		for _, j := range tree.search(item.Hash, threshold) {
+			if a, b := find(i), find(j); a != b {
+				parent[a] = b
+			}
+		}
+		tree.insert(item.Hash, i)
+	}
+
+	groups := map[int][]Item{}
+	var order []int
+	for i, item := range items {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], item)
+	}
+
+	var clusters [][]Item
+	for _, root := range order {
+		if len(groups[root]) > 1 {
+			clusters = append(clusters, groups[root])
+		}
+	}
+	return clusters
+}
+
+// bkTree indexes hashes by Hamming distance so that neighbours within a small
+// threshold are found without comparing against every hash.
+type bkTree struct {
+	root *bkNode
+}
+
+type bkNode struct {
+	hash     Hash
+	indexes  []int
+	children map[int]*bkNode
+}
+
+func (t *bkTree) insert(h Hash, index int) {
+	if t.root == nil {
+		t.root = &bkNode{hash: h, indexes: []int{index}}
+		return
+	}
+	node := t.root
+	for {
+		d := Distance(h, node.hash)
+		if d == 0 {
+			node.indexes = append(node.indexes, index)
+			return
+		}
+		child, ok := node.children[d]
+		if !ok {
+			if node.children == nil {
+				node.children = map[int]*bkNode{}
+			}
+			node.children[d] = &bkNode{hash: h, indexes: []int{index}}
+			return
+		}
+		node = child
+	}
+}
+
+func (t *bkTree) search(h Hash, threshold int) []int {
+	if t.root == nil {
+		return nil
+	}
+	var found []int
+	stack := []*bkNode{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		d := Distance(h, node.hash)
+		if d <= threshold {
+			found = append(found, node.indexes...)
+		}
+		// by the triangle inequality only children at distance d±threshold can match
+		for cd, child := range node.children {
+			if cd >= d-threshold && cd <= d+threshold {
+				stack = append(stack, child)
+			}
+		}
+	}
+	return found
+}
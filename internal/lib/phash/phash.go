@@ -0,0 +1,135 @@
+package phash
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+)
+
+// sampleSize is the side of the grayscale image the DCT runs on; the hash keeps
+// its lowest 8x8 frequencies.
+const sampleSize = 32
+
+// Hash is a DCT-based perceptual hash: a 64-bit fingerprint that stays close, by
+// Hamming distance, when an image is resized, re-encoded or slightly edited.
+type Hash uint64
+
+// Compute returns the perceptual hash of img.
+func Compute(img image.Image) Hash {
+	pixels := downsample(img)
+
+	// separable 2D DCT-II: rows first, then columns of the low frequencies only
+	var rows [sampleSize][8]float64
+	for y := 0; y < sampleSize; y++ {
+		for u := 0; u < 8; u++ {
+			var sum float64
+			for x := 0; x < sampleSize; x++ {
+				sum += pixels[y][x] * cosTable[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+	var coeffs [64]float64
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			var sum float64
+			for y := 0; y < sampleSize; y++ {
+				sum += rows[y][u] * cosTable[v][y]
+			}
+			coeffs[v*8+u] = sum
+		}
+	}
+
+	// the DC term only reflects overall brightness and is left out of the median
+	sorted := make([]float64, 63)
+	copy(sorted, coeffs[1:])
+	sort.Float64s(sorted)
+	median := (sorted[31] + sorted[32]) / 2
+
+	var h Hash
+	for i, c := range coeffs {
+		if c > median {
+			h |= 1 << uint(63-i)
+		}
+	}
+	return h
+}
+
+// Distance returns the number of differing bits between two hashes, from 0 to 64.
+func Distance(a, b Hash) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// String returns the hash as 16 hex digits.
+func (h Hash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// Parse parses a hash formatted by String.
+func Parse(s string) (Hash, error) {
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("phash: invalid hash %q", s)
+	}
+	return Hash(v), nil
+}
+
+var cosTable = func() (t [8][sampleSize]float64) {
+	for u := 0; u < 8; u++ {
+		for x := 0; x < sampleSize; x++ {
+			t[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * sampleSize))
+		}
+	}
+	return t
+}()
+
+// downsample averages the luminance of img over a sampleSize x sampleSize grid.
+// Averaging every source pixel, rather than sampling, keeps the result stable
+// across resized copies of the same image.
+func downsample(img image.Image) [sampleSize][sampleSize]float64 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	var sums [sampleSize][sampleSize]float64
+	var counts [sampleSize][sampleSize]int
+
+	luma := lumaFunc(img)
+	for y := 0; y < h; y++ {
+		gy := y * sampleSize / h
+		for x := 0; x < w; x++ {
+			gx := x * sampleSize / w
+			sums[gy][gx] += luma(b.Min.X+x, b.Min.Y+y)
+			counts[gy][gx]++
+		}
+	}
+
+	// images smaller than the grid leave cells empty; fill them from the nearest source pixel
+	for gy := 0; gy < sampleSize; gy++ {
+		for gx := 0; gx < sampleSize; gx++ {
+			if counts[gy][gx] > 0 {
+				sums[gy][gx] /= float64(counts[gy][gx])
+			} else {
+				sums[gy][gx] = luma(b.Min.X+gx*w/sampleSize, b.Min.Y+gy*h/sampleSize)
+			}
+		}
+	}
+	return sums
+}
+
+// lumaFunc returns a fast luminance accessor for the common decoded image types.
+func lumaFunc(img image.Image) func(x, y int) float64 {
+	switch m := img.(type) {
+	case *image.YCbCr:
+		return func(x, y int) float64 { return float64(m.Y[m.YOffset(x, y)]) }
+	case *image.Gray:
+		return func(x, y int) float64 { return float64(m.Pix[m.PixOffset(x, y)]) }
+	default:
+		return func(x, y int) float64 {
+			return float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package rle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+)
+
+// ErrInvalid is returned when counts do not describe a mask of the given size.
+var ErrInvalid = errors.New("invalid RLE")
+
+// MaxPixels bounds the size of a mask whose image has no known dimensions, so that
+// a small PNG or RLE cannot expand into an arbitrarily large bitmap.
+const MaxPixels = 100_000_000
+
+// RLE is a binary mask in COCO's run-length encoding: pixels are read column by
+// column, top to bottom, and Counts alternates runs of background and foreground
+// pixels, starting with background.
+type RLE struct {
+	Height int
+	Width  int
+	Counts []uint32
+}
+
+// FromImage encodes the mask in img. Pixels that are neither black nor fully
+// transparent are foreground.
+func FromImage(img image.Image) *RLE {
+	b := img.Bounds()
+	m := &RLE{Height: b.Dy(), Width: b.Dx()}
+
+	fg := false
+	var run uint32
+	for x := b.Min.X; x < b.Max.X; x++ {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			if on := a != 0 && (r|g|bl) != 0; on != fg {
+				m.Counts = append(m.Counts, run)
+				fg, run = on, 0
+			}
+			run++
+		}
+	}
+	m.Counts = append(m.Counts, run)
+	return m
+}
+
+// Validate checks that the runs cover exactly Height x Width pixels.
+func (m *RLE) Validate() error {
+	if m.Height <= 0 || m.Width <= 0 {
+		return fmt.Errorf("%w: size must be positive", ErrInvalid)
+	}
+	var total uint64
+	for _, c := range m.Counts {
+		total += uint64(c)
+	}
+	if want := uint64(m.Height) * uint64(m.Width); total != want {
+		return fmt.Errorf("%w: runs cover %d pixels, want %d", ErrInvalid, total, want)
+	}
+	return nil
+}
+
+// TooManyPixels reports whether a mask of the given size exceeds MaxPixels, without overflowing.
+func TooManyPixels(width, height int) bool {
+	return height > 0 && width > MaxPixels/height
+}
+
+// Area returns the number of foreground pixels.
+func (m *RLE) Area() int {
+	area := 0
+	for i := 1; i < len(m.Counts); i += 2 {
+		area += int(m.Counts[i])
+	}
+	return area
+}
+
+// Bounds returns the smallest rectangle of whole pixels holding the foreground.
+// It reports false for an empty mask.
+func (m *RLE) Bounds() (x, y, width, height int, ok bool) {
+	minX, minY := math.MaxInt, math.MaxInt
+	maxX, maxY := -1, -1
+
+	pos := 0
+	for i, c := range m.Counts {
+		start, end := pos, pos+int(c)
+		pos = end
+		if i%2 == 0 || c == 0 {
+			continue
+		}
+
+		firstCol, lastCol := start/m.Height, (end-1)/m.Height
+		minX, maxX = min(minX, firstCol), max(maxX, lastCol)
+		if firstCol == lastCol {
+			minY, maxY = min(minY, start%m.Height), max(maxY, (end-1)%m.Height)
+		} else {
+			// the run wraps into the next column, so it touches the top and the bottom
+			minY, maxY = 0, m.Height-1
+		}
+	}
+	if maxX < 0 {
+		return 0, 0, 0, 0, false
+	}
+	return minX, minY, maxX - minX + 1, maxY - minY + 1, true
+}
+
+// Image decodes the mask into a grayscale image with foreground at 255.
+func (m *RLE) Image() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, m.Width, m.Height))
+	pos := 0
+	for i, c := range m.Counts {
+		if i%2 == 1 {
+			for p := pos; p < pos+int(c); p++ {
+				img.Pix[(p%m.Height)*img.Stride+p/m.Height] = 255
+			}
+		}
+		pos += int(c)
+	}
+	return img
+}
+
+// String returns the counts in COCO's compressed form, as produced by pycocotools:
+// each count, from the third on relative to the one two before, is written as
+// little-endian groups of 5 bits in printable ASCII.
+func (m *RLE) String() string {
+	buf := make([]byte, 0, len(m.Counts)*2)
+	for i, c := range m.Counts {
+		x := int64(c)
+		if i > 2 {
+			x -= int64(m.Counts[i-2])
+		}
+		for more := true; more; {
+			b := byte(x & 0x1f)
+			x >>= 5
+			if b&0x10 != 0 {
+				more = x != -1
+			} else {
+				more = x != 0
+			}
+			if more {
+				b |= 0x20
+			}
+			buf = append(buf, b+48)
+		}
+	}
+	return string(buf)
+}
+
+// Parse decodes counts in COCO's compressed form for a mask of the given size.
+func Parse(height, width int, s string) (*RLE, error) {
+	m := &RLE{Height: height, Width: width}
+	for p := 0; p < len(s); {
+		var x int64
+		k := 0
+		for more := true; more; k++ {
+			if p >= len(s) || k > 12 {
+				return nil, fmt.Errorf("%w: truncated counts", ErrInvalid)
+			}
+			c := int64(s[p]) - 48
+			if c < 0 || c > 0x3f {
+				return nil, fmt.Errorf("%w: unexpected character %q", ErrInvalid, s[p])
+			}
+			x |= (c & 0x1f) << (5 * k)
+			more = c&0x20 != 0
+			p++
+			if !more && c&0x10 != 0 {
+				x |= -1 << (5 * (k + 1))
+			}
+		}
+		if i := len(m.Counts); i > 2 {
+			x += int64(m.Counts[i-2])
+		}
+		if x < 0 || x > math.MaxUint32 {
+			return nil, fmt.Errorf("%w: count out of range", ErrInvalid)
+		}
+		m.Counts = append(m.Counts, uint32(x))
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// rleJSON is COCO's RLE object; size is [height, width].
+type rleJSON struct {
+	Size   [2]int          `json:"size"`
+	Counts json.RawMessage `json:"counts"`
+}
+
+// MarshalJSON writes the COCO RLE object with compressed counts.
+func (m *RLE) MarshalJSON() ([]byte, error) {
+	counts, err := json.Marshal(m.String())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rleJSON{Size: [2]int{m.Height, m.Width}, Counts: counts})
+}
+
+// UnmarshalJSON reads a COCO RLE object whose counts are either compressed or a plain array.
+func (m *RLE) UnmarshalJSON(data []byte) error {
+	var raw rleJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var compressed string
+	if err := json.Unmarshal(raw.Counts, &compressed); err == nil {
+		parsed, err := Parse(raw.Size[0], raw.Size[1], compressed)
+		if err != nil {
+			return err
+		}
+		*m = *parsed
+		return nil
+	}
+
+	var counts []uint32
+	if err := json.Unmarshal(raw.Counts, &counts); err != nil {
+		return fmt.Errorf("%w: counts must be a string or an array of counts", ErrInvalid)
+	}
+	parsed := &RLE{Height: raw.Size[0], Width: raw.Size[1], Counts: counts}
+	if err := parsed.Validate(); err != nil {
+		return err
+	}
+	*m = *parsed
+	return nil
+}
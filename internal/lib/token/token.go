@@ -0,0 +1,95 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Manager issues and verifies signed access tokens and opaque refresh tokens.
+type Manager struct {
+	secret     []byte
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewManager creates a new token manager signing access tokens with HS256.
+func NewManager(secret, issuer string, accessTTL, refreshTTL time.Duration) *Manager {
+	return &Manager{
+		secret:     []byte(secret),
+		issuer:     issuer,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// AccessTTL returns the lifetime of issued access tokens.
+func (m *Manager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+// RefreshTTL returns the lifetime of issued refresh tokens.
+func (m *Manager) RefreshTTL() time.Duration {
+	return m.refreshTTL
+}
+
+// NewAccessToken returns a signed JWT whose subject is the user ID.
+func (m *Manager) NewAccessToken(userID string) (string, error) {
+	const op = "token.Manager.NewAccessToken"
+
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   userID,
+		Issuer:    m.issuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(m.accessTTL)),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return signed, nil
+}
+
+// ParseAccessToken verifies the signature, issuer and expiry of an access token and returns its subject.
+func (m *Manager) ParseAccessToken(raw string) (string, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(
+		raw,
+		&claims,
+		func(*jwt.Token) (any, error) { return m.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Subject == "" {
+		return "", ErrInvalidToken
+	}
+	return claims.Subject, nil
+}
+
+// NewRefreshToken returns a random opaque refresh token and the hash under which it should be stored.
+func NewRefreshToken() (raw string, hashed string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("token.NewRefreshToken: %w", err)
+	}
+	raw = base64.RawURLEncoding.EncodeToString(b)
+	return raw, HashRefreshToken(raw), nil
+}
+
+// HashRefreshToken returns the hex-encoded SHA-256 of a refresh token. Only hashes are persisted.
+func HashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
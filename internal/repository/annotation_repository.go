@@ -1,21 +1,43 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/lib/pq"
+)
+
+// Annotation review statuses.
+const (
+	AnnotationPending  = "pending"
+	AnnotationApproved = "approved"
+	AnnotationRejected = "rejected"
 )
 
 // Annotation represents an annotation in the database - Model
 type Annotation struct {
-	ID        string `json:"id"`
-	ImageID   string `json:"image_id"`
-	UserID    string `json:"user_id"`
-	X         int    `json:"x"`
-	Y         int    `json:"y"`
-	Width     int    `json:"width"`
-	Height    int    `json:"height"`
-	Comment   string `json:"comment"`
-	CreatedAt string `json:"created_at"`
+	ID      string `json:"id"`
+	ImageID string `json:"image_id"`
+	UserID  string `json:"user_id"`
+	// LabelID is empty for unlabeled annotations
+	LabelID string  `json:"label_id,omitempty"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Width   float64 `json:"width"`
+	Height  float64 `json:"height"`
+	// Geometry holds the shape; for non-box types X, Y, Width and Height are its bounding box
+	Geometry *geometry.Geometry `json:"geometry"`
+	// Attributes are free-form flags such as "difficult" or "truncated"
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Comment    string         `json:"comment"`
+	Status     string         `json:"status"`
+	ReviewedBy string         `json:"reviewed_by,omitempty"`
+	ReviewedAt string         `json:"reviewed_at,omitempty"`
+	CreatedAt  string         `json:"created_at"`
 }
 
 // AnnotationRepository is a struct that provides methods to interact with the annotation database table. Implements the Annotations interface.
@@ -23,116 +45,415 @@ type AnnotationRepository struct {
 	db *sql.DB
 }
 
-// Create inserts a new annotation into the database. It returns an error if the insertion fails.
-func (r *AnnotationRepository) Create(annotation *Annotation) error {
-	query := `INSERT INTO annotations (image_id, user_id, x, y, width, height, comment) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+const annotationColumns = `id, image_id, user_id, label_id, x, y, width, height, geometry, attributes, comment, status, reviewed_by, reviewed_at, created_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
+func scanAnnotation(row rowScanner) (*Annotation, error) {
+	var annotation Annotation
+	var labelID, shape, attributes, reviewedBy, reviewedAt sql.NullString
+	if err := row.Scan(
+		&annotation.ID,
+		&annotation.ImageID,
+		&annotation.UserID,
+		&labelID,
+		&annotation.X,
+		&annotation.Y,
+		&annotation.Width,
+		&annotation.Height,
+		&shape,
+		&attributes,
+		&annotation.Comment,
+		&annotation.Status,
+		&reviewedBy,
+		&reviewedAt,
+		&annotation.CreatedAt); err != nil {
+		return nil, err
+	}
+	annotation.LabelID = labelID.String
+	// boxes predate geometry types and are stored without one
+	annotation.Geometry = &geometry.Geometry{Type: geometry.Box}
+	if shape.Valid {
+		if err := json.Unmarshal([]byte(shape.String), annotation.Geometry); err != nil {
+			return nil, fmt.Errorf("decode geometry: %w", err)
+		}
+	}
+	if attributes.Valid {
+		if err := json.Unmarshal([]byte(attributes.String), &annotation.Attributes); err != nil {
+			return nil, fmt.Errorf("decode attributes: %w", err)
+		}
+	}
+	annotation.ReviewedBy = reviewedBy.String
+	annotation.ReviewedAt = reviewedAt.String
+	return &annotation, nil
+}
+
+// geometryArg encodes the geometry for the JSONB column; axis-aligned boxes are stored as NULL.
+func geometryArg(g *geometry.Geometry) (sql.NullString, error) {
+	if g == nil || (g.Type == geometry.Box && g.Angle == 0) {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(g)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// attributesArg encodes the attributes for the JSONB column; annotations without any store NULL.
+func attributesArg(attributes map[string]any) (sql.NullString, error) {
+	if len(attributes) == 0 {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(attributes)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Create inserts a new annotation into the database. It returns an error if the insertion fails.
+func (r *AnnotationRepository) Create(ctx context.Context, annotation *Annotation) error {
 	const op = "repository.AnnotationRepository.Create"
 
-	err := r.db.QueryRow(
+	if err := insertAnnotation(ctx, r.db, annotation); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
+
+func insertAnnotation(ctx context.Context, q querier, annotation *Annotation) error {
+	query := `INSERT INTO annotations (image_id, user_id, label_id, x, y, width, height, geometry, attributes, comment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, status, created_at`
+
+	shape, err := geometryArg(annotation.Geometry)
+	if err != nil {
+		return err
+	}
+	attributes, err := attributesArg(annotation.Attributes)
+	if err != nil {
+		return err
+	}
+
+	return q.QueryRowContext(
+		ctx,
 		query,
 		annotation.ImageID,
 		annotation.UserID,
+		sql.NullString{String: annotation.LabelID, Valid: annotation.LabelID != ""},
 		annotation.X,
 		annotation.Y,
 		annotation.Width,
 		annotation.Height,
+		shape,
+		attributes,
 		annotation.Comment,
-	).Scan(&annotation.ID, &annotation.CreatedAt)
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	return nil
+	).Scan(&annotation.ID, &annotation.Status, &annotation.CreatedAt)
 }
 
-// GetAll retrieves all annotations from the database.
-func (r *AnnotationRepository) GetAll() ([]*Annotation, error) {
-	query := `SELECT id, image_id, user_id, x, y, width, height, comment, created_at FROM annotations`
+// annotationList sorts annotations by id or created_at and filters them by exact status, author (user_id),
+// label_id or geometry type.
+var annotationList = listSpec{
+	id: column{"id", "int"},
+	sorts: map[string]column{
+		"id":         {"id", "int"},
+		"created_at": {"created_at", "timestamp"},
+	},
+	defaultSort: "id",
+	filters: map[string]filter{
+		"status":   {column{"status", "text"}, "="},
+		"user_id":  {column{"user_id", "int"}, "="},
+		"label_id": {column{"label_id", "int"}, "="},
+		"type":     {column{"COALESCE(geometry->>'type', 'box')", "text"}, "="},
+	},
+}
 
+// GetAll retrieves a page of annotations across all images and the cursor of the next page.
+func (r *AnnotationRepository) GetAll(ctx context.Context, opts ListOptions) ([]*Annotation, string, error) {
 	const op = "repository.AnnotationRepository.GetAll"
 
-	rows, err := r.db.Query(query)
+	return r.list(ctx, op, opts, nil, nil)
+}
+
+// GetByImageID retrieves a page of the annotations attached to the given image and the cursor of the next page.
+func (r *AnnotationRepository) GetByImageID(ctx context.Context, imageID string, opts ListOptions) ([]*Annotation, string, error) {
+	const op = "repository.AnnotationRepository.GetByImageID"
+
+	return r.list(ctx, op, opts, []string{"image_id = $1"}, []any{imageID})
+}
+
+// GetByImageIDs retrieves all annotations of the given images in a single query, ordered
+// by image and then by id, e.g. to export a page of images at once.
+func (r *AnnotationRepository) GetByImageIDs(ctx context.Context, imageIDs []string) ([]*Annotation, error) {
+	query := `SELECT ` + annotationColumns + ` FROM annotations WHERE image_id = ANY($1::int[]) ORDER BY image_id, id`
+
+	const op = "repository.AnnotationRepository.GetByImageIDs"
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(imageIDs))
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, mapError(op, err)
 	}
 	defer rows.Close()
 
 	var annotations []*Annotation
 	for rows.Next() {
-		var annotation Annotation
-		if err := rows.Scan(
-			&annotation.ID,
-			&annotation.ImageID,
-			&annotation.UserID,
-			&annotation.X,
-			&annotation.Y,
-			&annotation.Width,
-			&annotation.Height,
-			&annotation.Comment,
-			&annotation.CreatedAt); err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+		annotation, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, mapError(op, err)
 		}
-		annotations = append(annotations, &annotation)
+		annotations = append(annotations, annotation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(op, err)
 	}
 	return annotations, nil
 }
 
-// GetByID retrieves an annotation by its ID from the database. Does not return an error if the annotation is not found.
-func (r *AnnotationRepository) GetByID(id string) (*Annotation, error) {
-	query := `SELECT id, image_id, user_id, x, y, width, height, comment, created_at FROM annotations WHERE id = $1`
-
-	const op = "repository.AnnotationRepository.GetByID"
+func (r *AnnotationRepository) list(ctx context.Context, op string, opts ListOptions, conds []string, args []any) ([]*Annotation, string, error) {
+	tail, args, err := annotationList.build(opts, conds, args)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	query := `SELECT ` + annotationColumns + ` FROM annotations` + tail
 
-	row := r.db.QueryRow(query, id)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", mapError(op, err)
+	}
+	defer rows.Close()
 
-	var annotation Annotation
-	if err := row.Scan(
-		&annotation.ID,
-		&annotation.ImageID,
-		&annotation.UserID,
-		&annotation.X,
-		&annotation.Y,
-		&annotation.Width,
-		&annotation.Height,
-		&annotation.Comment,
-		&annotation.CreatedAt); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
+	var annotations []*Annotation
+	for rows.Next() {
+		annotation, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, "", mapError(op, err)
 		}
-		return nil, fmt.Errorf("%s: %w", op, err)
+		annotations = append(annotations, annotation)
 	}
-	return &annotation, nil
+	if err := rows.Err(); err != nil {
+		return nil, "", mapError(op, err)
+	}
+
+	annotations, next := paginate(annotations, opts, annotationList.defaultSort, func(a *Annotation, sort string) (string, string) {
+		if sort == "created_at" {
+			return a.CreatedAt, a.ID
+		}
+		return a.ID, a.ID
+	})
+	return annotations, next, nil
 }
 
-// Update modifies an existing annotation in the database. It returns an error if the update fails.
-func (r *AnnotationRepository) Update(annotation *Annotation) error {
-	query := `UPDATE annotations SET  x = $1, y = $2, width = $3, height = $4, comment = $5 WHERE id = $6`
+// GetByID retrieves an annotation by its ID from the database. Returns ErrNotFound if the annotation does not exist.
+func (r *AnnotationRepository) GetByID(ctx context.Context, id string) (*Annotation, error) {
+	query := `SELECT ` + annotationColumns + ` FROM annotations WHERE id = $1`
+
+	const op = "repository.AnnotationRepository.GetByID"
+
+	annotation, err := scanAnnotation(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	return annotation, nil
+}
 
+// Update modifies an existing annotation in the database. Any edit sends the annotation back to review.
+// It returns an error if the update fails.
+func (r *AnnotationRepository) Update(ctx context.Context, annotation *Annotation) error {
 	const op = "repository.AnnotationRepository.Update"
 
-	_, err := r.db.Exec(query,
+	return updateAnnotation(ctx, r.db, op, annotation)
+}
+
+func updateAnnotation(ctx context.Context, q querier, op string, annotation *Annotation) error {
+	query := `UPDATE annotations SET label_id = $1, x = $2, y = $3, width = $4, height = $5, geometry = $6, attributes = $7,
+		comment = $8, status = 'pending', reviewed_by = NULL, reviewed_at = NULL WHERE id = $9`
+
+	shape, err := geometryArg(annotation.Geometry)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	attributes, err := attributesArg(annotation.Attributes)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := q.ExecContext(ctx, query,
+		sql.NullString{String: annotation.LabelID, Valid: annotation.LabelID != ""},
 		annotation.X,
 		annotation.Y,
 		annotation.Width,
 		annotation.Height,
+		shape,
+		attributes,
 		annotation.Comment,
 		annotation.ID,
 	)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return mapError(op, err)
+	}
+	if err := expectAffected(op, res); err != nil {
+		return err
+	}
+	annotation.Status = AnnotationPending
+	annotation.ReviewedBy = ""
+	annotation.ReviewedAt = ""
+	return nil
+}
+
+// Review records a reviewer's decision on an annotation. It returns an error if the update fails.
+func (r *AnnotationRepository) Review(ctx context.Context, annotation *Annotation) error {
+	query := `UPDATE annotations SET status = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP WHERE id = $3 RETURNING reviewed_at`
+
+	const op = "repository.AnnotationRepository.Review"
+
+	err := r.db.QueryRowContext(ctx, query,
+		annotation.Status,
+		annotation.ReviewedBy,
+		annotation.ID,
+	).Scan(&annotation.ReviewedAt)
+	if err != nil {
+		return mapError(op, err)
 	}
 	return nil
 }
 
 // Delete removes an annotation from the database by its ID. It returns an error if the deletion fails.
-func (r *AnnotationRepository) Delete(id string) error {
+func (r *AnnotationRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM annotations WHERE id = $1`
 
 	const op = "repository.AnnotationRepository.Delete"
 
-	_, err := r.db.Exec(query, id)
+	res, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return mapError(op, err)
+	}
+	return expectAffected(op, res)
+}
+
+// CreateMask inserts a mask annotation together with its pixels. The geometry, area and
+// bounding box of the annotation are derived from the mask.
+func (r *AnnotationRepository) CreateMask(ctx context.Context, annotation *Annotation, mask *rle.RLE) error {
+	const op = "repository.AnnotationRepository.CreateMask"
+
+	applyMask(annotation, mask)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(op, err)
+	}
+	defer tx.Rollback()
+
+	if err := insertAnnotation(ctx, tx, annotation); err != nil {
+		return mapError(op, err)
+	}
+	if err := putMask(ctx, tx, annotation.ID, mask); err != nil {
+		return mapError(op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapError(op, err)
 	}
 	return nil
 }
+
+// GetMask retrieves the pixels of a mask annotation. Returns ErrNotFound if the annotation has no mask.
+func (r *AnnotationRepository) GetMask(ctx context.Context, annotationID string) (*rle.RLE, error) {
+	query := `SELECT height, width, counts FROM annotation_masks WHERE annotation_id = $1`
+
+	const op = "repository.AnnotationRepository.GetMask"
+
+	var height, width int
+	var counts string
+	if err := r.db.QueryRowContext(ctx, query, annotationID).Scan(&height, &width, &counts); err != nil {
+		return nil, mapError(op, err)
+	}
+
+	mask, err := rle.Parse(height, width, counts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return mask, nil
+}
+
+// GetMasks retrieves the pixels of the given mask annotations in a single query, keyed by
+// annotation ID. Annotations without a mask are left out.
+func (r *AnnotationRepository) GetMasks(ctx context.Context, annotationIDs []string) (map[string]*rle.RLE, error) {
+	query := `SELECT annotation_id, height, width, counts FROM annotation_masks WHERE annotation_id = ANY($1::int[])`
+
+	const op = "repository.AnnotationRepository.GetMasks"
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(annotationIDs))
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	defer rows.Close()
+
+	masks := make(map[string]*rle.RLE, len(annotationIDs))
+	for rows.Next() {
+		var id, counts string
+		var height, width int
+		if err := rows.Scan(&id, &height, &width, &counts); err != nil {
+			return nil, mapError(op, err)
+		}
+		mask, err := rle.Parse(height, width, counts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: annotation %s: %w", op, id, err)
+		}
+		masks[id] = mask
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(op, err)
+	}
+	return masks, nil
+}
+
+// UpdateMask replaces the pixels of a mask annotation along with its other fields, and sends
+// it back to review like any other edit.
+func (r *AnnotationRepository) UpdateMask(ctx context.Context, annotation *Annotation, mask *rle.RLE) error {
+	const op = "repository.AnnotationRepository.UpdateMask"
+
+	applyMask(annotation, mask)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(op, err)
+	}
+	defer tx.Rollback()
+
+	if err := updateAnnotation(ctx, tx, op, annotation); err != nil {
+		return err
+	}
+	if err := putMask(ctx, tx, annotation.ID, mask); err != nil {
+		return mapError(op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
+
+// applyMask sets the mask geometry and the bounding box of its foreground on the annotation.
+func applyMask(annotation *Annotation, mask *rle.RLE) {
+	annotation.Geometry = &geometry.Geometry{Type: geometry.Mask, Area: mask.Area()}
+	x, y, width, height, _ := mask.Bounds()
+	annotation.X, annotation.Y = float64(x), float64(y)
+	annotation.Width, annotation.Height = float64(width), float64(height)
+}
+
+func putMask(ctx context.Context, q querier, annotationID string, mask *rle.RLE) error {
+	query := `INSERT INTO annotation_masks (annotation_id, height, width, counts) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (annotation_id) DO UPDATE SET height = EXCLUDED.height, width = EXCLUDED.width, counts = EXCLUDED.counts`
+
+	_, err := q.ExecContext(ctx, query, annotationID, mask.Height, mask.Width, mask.String())
+	return err
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound is returned when the requested row does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is matched by every *DuplicateError via errors.Is.
+	ErrDuplicate = errors.New("duplicate")
+	// ErrForeignKey is returned when a row references another row that does not exist.
+	ErrForeignKey = errors.New("referenced row does not exist")
+)
+
+// DuplicateError is returned when a write violates a unique constraint. Field names the offending column.
+type DuplicateError struct {
+	Field string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate %s", e.Field)
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// Postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+	pqInvalidTextRepr     = "22P02"
+)
+
+// mapError translates driver errors into the repository's typed errors and wraps them with op.
+func mapError(op string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, ErrNotFound)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			return fmt.Errorf("%s: %w", op, &DuplicateError{Field: constraintField(pqErr.Table, pqErr.Constraint)})
+		case pqForeignKeyViolation:
+			return fmt.Errorf("%s: %w: %s", op, ErrForeignKey, pqErr.Constraint)
+		case pqInvalidTextRepr:
+			// a malformed id such as "abc" for a SERIAL column cannot match any row
+			return fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// constraintField derives the column name from a Postgres default constraint name, e.g. users_email_key -> email.
+func constraintField(table, constraint string) string {
+	field := strings.TrimPrefix(constraint, table+"_")
+	field = strings.TrimSuffix(field, "_key")
+	return field
+}
+
+// expectAffected returns ErrNotFound if a write touched no rows.
+func expectAffected(op string, res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ImageMemberRepository is a struct that provides methods to interact with the image_members database table.
+// A member can read a private image they do not own. Implements the ImageMembers interface.
+type ImageMemberRepository struct {
+	db *sql.DB
+}
+
+// Add grants the user access to the image. Adding an existing member is a no-op.
+func (r *ImageMemberRepository) Add(ctx context.Context, imageID, userID string) error {
+	query := `INSERT INTO image_members (image_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	const op = "repository.ImageMemberRepository.Add"
+
+	if _, err := r.db.ExecContext(ctx, query, imageID, userID); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
+
+// Remove revokes the user's access to the image.
+func (r *ImageMemberRepository) Remove(ctx context.Context, imageID, userID string) error {
+	query := `DELETE FROM image_members WHERE image_id = $1 AND user_id = $2`
+
+	const op = "repository.ImageMemberRepository.Remove"
+
+	if _, err := r.db.ExecContext(ctx, query, imageID, userID); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
+
+// IsMember reports whether the user has been granted access to the image.
+func (r *ImageMemberRepository) IsMember(ctx context.Context, imageID, userID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM image_members WHERE image_id = $1 AND user_id = $2)`
+
+	const op = "repository.ImageMemberRepository.IsMember"
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, imageID, userID).Scan(&exists); err != nil {
+		return false, mapError(op, err)
+	}
+	return exists, nil
+}
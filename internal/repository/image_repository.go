@@ -1,19 +1,66 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 )
 
 // Image represents an image in the database - Model
 type Image struct {
-	ID          string `json:"id"`
-	UserID      string `json:"user_id"`
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	// ProjectID is empty for personal images outside any project
+	ProjectID   string `json:"project_id,omitempty"`
 	URL         string `json:"url"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Visibility  bool   `json:"visibility"`
-	CreatedAt   string `json:"created_at"`
+	// ObjectKey is the storage key of the uploaded file; empty for images registered by URL
+	ObjectKey string `json:"object_key,omitempty"`
+	// ContentHash is the hex SHA-256 of the uploaded file; empty for registered images
+	ContentHash string `json:"content_hash,omitempty"`
+	// PerceptualHash is a 16-digit hex fingerprint used to find resized or re-encoded copies
+	PerceptualHash string `json:"phash,omitempty"`
+	// Metadata is extracted from the file on ingest; nil when the file could not be read
+	Metadata *ImageMetadata `json:"metadata,omitempty"`
+	// ThumbnailStatus is empty for registered images, which have no stored file to scale
+	ThumbnailStatus string      `json:"thumbnail_status,omitempty"`
+	Thumbnails      []Thumbnail `json:"thumbnails,omitempty"`
+	CreatedAt       string      `json:"created_at"`
+}
+
+// Thumbnail statuses of uploaded images.
+const (
+	ThumbnailPending = "pending"
+	ThumbnailReady   = "ready"
+	ThumbnailFailed  = "failed"
+)
+
+// Thumbnail is a scaled-down copy of an uploaded image. Size is the requested
+// length of the longest side; Width and Height are the actual dimensions.
+type Thumbnail struct {
+	Size      int    `json:"size"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	URL       string `json:"url"`
+	ObjectKey string `json:"object_key"`
+}
+
+// ImageMetadata describes the decoded image file.
+type ImageMetadata struct {
+	Width       int        `json:"width"`
+	Height      int        `json:"height"`
+	MimeType    string     `json:"mime_type"`
+	SizeBytes   int64      `json:"size_bytes"`
+	ColorModel  string     `json:"color_model"`
+	CapturedAt  *time.Time `json:"captured_at,omitempty"`
+	CameraMake  string     `json:"camera_make,omitempty"`
+	CameraModel string     `json:"camera_model,omitempty"`
+	Orientation int        `json:"orientation,omitempty"`
 }
 
 // ImageRepository is a struct that provides methods to interact with the image database table. Implements the Images interface.
@@ -21,110 +68,443 @@ type ImageRepository struct {
 	db *sql.DB
 }
 
-// Create inserts a new image into the database. It returns an error if the insertion fails.
-func (r *ImageRepository) Create(image *Image) error {
-	query := "INSERT INTO images (user_id, url, title, description, visibility) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at"
+const imageColumns = `id, user_id, project_id, url, title, description, visibility, object_key, content_hash, phash,
+	width, height, mime_type, size_bytes, color_model, captured_at, camera_make, camera_model, orientation,
+	thumbnail_status, thumbnails, created_at`
+
+func scanImage(row rowScanner) (*Image, error) {
+	var image Image
+	var projectID, objectKey, contentHash sql.NullString
+	var pHash, width, height, sizeBytes, orientation sql.NullInt64
+	var mimeType, colorModel, cameraMake, cameraModel sql.NullString
+	var capturedAt sql.NullTime
+	var thumbnailStatus sql.NullString
+	var thumbnails []byte
+	if err := row.Scan(
+		&image.ID,
+		&image.UserID,
+		&projectID,
+		&image.URL,
+		&image.Title,
+		&image.Description,
+		&image.Visibility,
+		&objectKey,
+		&contentHash,
+		&pHash,
+		&width,
+		&height,
+		&mimeType,
+		&sizeBytes,
+		&colorModel,
+		&capturedAt,
+		&cameraMake,
+		&cameraModel,
+		&orientation,
+		&thumbnailStatus,
+		&thumbnails,
+		&image.CreatedAt); err != nil {
+		return nil, err
+	}
+	image.ProjectID = projectID.String
+	image.ObjectKey = objectKey.String
+	image.ContentHash = contentHash.String
+	if pHash.Valid {
+		image.PerceptualHash = formatPHash(pHash.Int64)
+	}
+	image.ThumbnailStatus = thumbnailStatus.String
+	if thumbnails != nil {
+		if err := json.Unmarshal(thumbnails, &image.Thumbnails); err != nil {
+			return nil, err
+		}
+	}
+	if width.Valid && height.Valid {
+		image.Metadata = &ImageMetadata{
+			Width:       int(width.Int64),
+			Height:      int(height.Int64),
+			MimeType:    mimeType.String,
+			SizeBytes:   sizeBytes.Int64,
+			ColorModel:  colorModel.String,
+			CameraMake:  cameraMake.String,
+			CameraModel: cameraModel.String,
+			Orientation: int(orientation.Int64),
+		}
+		if capturedAt.Valid {
+			image.Metadata.CapturedAt = &capturedAt.Time
+		}
+	}
+	return &image, nil
+}
+
+// ImageHash is the perceptual hash of an image, as listed for near-duplicate detection.
+type ImageHash struct {
+	ImageID        string
+	PerceptualHash string
+}
+
+// Perceptual hashes are 64-bit unsigned values stored bit for bit in a BIGINT.
+func formatPHash(v int64) string {
+	return fmt.Sprintf("%016x", uint64(v))
+}
+
+func phashArg(s string) (sql.NullInt64, error) {
+	if s == "" {
+		return sql.NullInt64{}, nil
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("invalid perceptual hash %q", s)
+	}
+	return sql.NullInt64{Int64: int64(v), Valid: true}, nil
+}
+
+// metadataArgs returns the metadata column values in imageColumns order, all NULL when m is nil.
+func metadataArgs(m *ImageMetadata) []any {
+	if m == nil {
+		return []any{nil, nil, nil, nil, nil, nil, nil, nil, nil}
+	}
+	var capturedAt sql.NullTime
+	if m.CapturedAt != nil {
+		capturedAt = sql.NullTime{Time: *m.CapturedAt, Valid: true}
+	}
+	return []any{
+		m.Width,
+		m.Height,
+		m.MimeType,
+		m.SizeBytes,
+		m.ColorModel,
+		capturedAt,
+		sql.NullString{String: m.CameraMake, Valid: m.CameraMake != ""},
+		sql.NullString{String: m.CameraModel, Valid: m.CameraModel != ""},
+		sql.NullInt64{Int64: int64(m.Orientation), Valid: m.Orientation != 0},
+	}
+}
 
+// Create inserts a new image into the database. It returns an error if the insertion fails.
+func (r *ImageRepository) Create(ctx context.Context, image *Image) error {
 	const op = "repository.ImageRepository.Create"
 
-	err := r.db.QueryRow(
-		query,
+	if err := insertImage(ctx, r.db, image); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
+
+func insertImage(ctx context.Context, q querier, image *Image) error {
+	query := `INSERT INTO images (user_id, project_id, url, title, description, visibility, object_key, content_hash, thumbnail_status, phash,
+		width, height, mime_type, size_bytes, color_model, captured_at, camera_make, camera_model, orientation)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING id, created_at`
+
+	pHash, err := phashArg(image.PerceptualHash)
+	if err != nil {
+		return err
+	}
+
+	args := append([]any{
 		image.UserID,
+		sql.NullString{String: image.ProjectID, Valid: image.ProjectID != ""},
 		image.URL,
 		image.Title,
 		image.Description,
 		image.Visibility,
-	).Scan(&image.ID, &image.CreatedAt)
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	return nil
+		sql.NullString{String: image.ObjectKey, Valid: image.ObjectKey != ""},
+		sql.NullString{String: image.ContentHash, Valid: image.ContentHash != ""},
+		sql.NullString{String: image.ThumbnailStatus, Valid: image.ThumbnailStatus != ""},
+		pHash,
+	}, metadataArgs(image.Metadata)...)
+
+	return q.QueryRowContext(ctx, query, args...).Scan(&image.ID, &image.CreatedAt)
 }
 
-// GetAll retrieves all images from the database.
-func (r *ImageRepository) GetAll() ([]*Image, error) {
-	query := "SELECT id, user_id, url, title, description, visibility, created_at FROM images"
+// imageList sorts images by id, created_at or title and filters them by owner (user_id),
+// project_id, visibility, mime_type and minimum or maximum dimensions (min_width, max_width,
+// min_height, max_height).
+var imageList = listSpec{
+	id: column{"id", "int"},
+	sorts: map[string]column{
+		"id":         {"id", "int"},
+		"created_at": {"created_at", "timestamp"},
+		"title":      {"title", "text"},
+	},
+	defaultSort: "id",
+	filters: map[string]filter{
+		"user_id":    {column{"user_id", "int"}, "="},
+		"project_id": {column{"project_id", "int"}, "="},
+		"visibility": {column{"visibility", "boolean"}, "="},
+		"mime_type":  {column{"mime_type", "text"}, "="},
+		"min_width":  {column{"width", "int"}, ">="},
+		"max_width":  {column{"width", "int"}, "<="},
+		"min_height": {column{"height", "int"}, ">="},
+		"max_height": {column{"height", "int"}, "<="},
+	},
+}
 
+// GetAll retrieves a page of all images and the cursor of the next page.
+func (r *ImageRepository) GetAll(ctx context.Context, opts ListOptions) ([]*Image, string, error) {
 	const op = "repository.ImageRepository.GetAll"
 
-	rows, err := r.db.Query(query)
+	return r.list(ctx, op, opts, nil, nil)
+}
+
+// GetAllVisibleTo retrieves a page of the images the user may read: public images, images
+// of their projects, and personal images they own or that were shared with them. It also
+// returns the cursor of the next page.
+func (r *ImageRepository) GetAllVisibleTo(ctx context.Context, userID string, opts ListOptions) ([]*Image, string, error) {
+	const op = "repository.ImageRepository.GetAllVisibleTo"
+
+	visible := `(visibility
+		OR (project_id IS NULL AND (user_id = $1
+			OR EXISTS (SELECT 1 FROM image_members m WHERE m.image_id = images.id AND m.user_id = $1)))
+		OR EXISTS (SELECT 1 FROM project_members pm WHERE pm.project_id = images.project_id AND pm.user_id = $1))`
+
+	return r.list(ctx, op, opts, []string{visible}, []any{userID})
+}
+
+// GetByID retrieves an image by its ID from the database. Returns ErrNotFound if the image does not exist.
+func (r *ImageRepository) GetByID(ctx context.Context, id string) (*Image, error) {
+	query := "SELECT " + imageColumns + " FROM images WHERE id = $1"
+
+	const op = "repository.ImageRepository.GetByID"
+
+	image, err := scanImage(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, mapError(op, err)
 	}
-	defer rows.Close()
+	return image, nil
+}
 
-	var images []*Image
-	for rows.Next() {
-		var image Image
-		if err := rows.Scan(
-			&image.ID,
-			&image.UserID,
-			&image.URL,
-			&image.Title,
-			&image.Description,
-			&image.Visibility,
-			&image.CreatedAt); err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-		images = append(images, &image)
+// GetByContentHash retrieves the image in the project whose uploaded file has the given SHA-256.
+// Without a project it looks among the user's personal images. Returns ErrNotFound if there is none.
+func (r *ImageRepository) GetByContentHash(ctx context.Context, userID, projectID, hash string) (*Image, error) {
+	const op = "repository.ImageRepository.GetByContentHash"
+
+	query := "SELECT " + imageColumns + " FROM images WHERE project_id = $1 AND content_hash = $2"
+	scope := projectID
+	if projectID == "" {
+		query = "SELECT " + imageColumns + " FROM images WHERE user_id = $1 AND project_id IS NULL AND content_hash = $2"
+		scope = userID
 	}
-	return images, nil
+
+	image, err := scanImage(r.db.QueryRowContext(ctx, query, scope, hash))
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	return image, nil
 }
 
-// GetByID retrieves an image by its ID from the database. Does not return an error if the image is not found.
-func (r *ImageRepository) GetByID(id string) (*Image, error) {
-	query := "SELECT id, user_id, url, title, description, visibility, created_at FROM images WHERE id = $1"
+// HasObjectKey reports whether any image references the stored file.
+func (r *ImageRepository) HasObjectKey(ctx context.Context, key string) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM images WHERE object_key = $1)"
 
-	const op = "repository.ImageRepository.GetByID"
+	const op = "repository.ImageRepository.HasObjectKey"
 
-	row := r.db.QueryRow(query, id)
-
-	var image Image
-	if err := row.Scan(
-		&image.ID,
-		&image.UserID,
-		&image.URL,
-		&image.Title,
-		&image.Description,
-		&image.Visibility,
-		&image.CreatedAt); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("%s: %w", op, err)
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, key).Scan(&exists); err != nil {
+		return false, mapError(op, err)
 	}
-	return &image, nil
+	return exists, nil
 }
 
 // Update modifies an existing image in the database. It returns an error if the update fails.
-func (r *ImageRepository) Update(image *Image) error {
-	query := "UPDATE images SET url = $1, title = $2, description = $3, visibility = $4 WHERE id = $5"
+// When the image moves to another project, or out of one, labels of its annotations are
+// remapped to the active label of the same name in the new project, or cleared, so that
+// no annotation keeps a label of another project's taxonomy.
+func (r *ImageRepository) Update(ctx context.Context, image *Image) error {
+	query := `UPDATE images SET project_id = $1, url = $2, title = $3, description = $4, visibility = $5, phash = $6,
+		width = $7, height = $8, mime_type = $9, size_bytes = $10, color_model = $11,
+		captured_at = $12, camera_make = $13, camera_model = $14, orientation = $15
+		WHERE id = $16`
 
 	const op = "repository.ImageRepository.Update"
 
-	_, err := r.db.Exec(
-		query,
+	pHash, err := phashArg(image.PerceptualHash)
+	if err != nil {
+		return mapError(op, err)
+	}
+
+	projectID := sql.NullString{String: image.ProjectID, Valid: image.ProjectID != ""}
+	args := append([]any{
+		projectID,
 		image.URL,
 		image.Title,
 		image.Description,
 		image.Visibility,
+		pHash,
+	}, metadataArgs(image.Metadata)...)
+	args = append(args, image.ID)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return mapError(op, err)
+	}
+	if err := expectAffected(op, res); err != nil {
+		return err
+	}
+
+	// a no-op unless the image changed project
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE annotations a SET label_id = (
+			SELECT n.id FROM labels n JOIN labels o ON o.name = n.name
+			WHERE o.id = a.label_id AND n.project_id = $2 AND n.archived_at IS NULL)
+		WHERE a.image_id = $1
+			AND a.label_id IN (SELECT id FROM labels WHERE project_id IS DISTINCT FROM $2)`,
 		image.ID,
+		projectID,
 	)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return mapError(op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapError(op, err)
 	}
 	return nil
 }
 
 // Delete removes an image from the database by its ID. It returns an error if the deletion fails.
-func (r *ImageRepository) Delete(id string) error {
+func (r *ImageRepository) Delete(ctx context.Context, id string) error {
 	query := "DELETE FROM images WHERE id = $1"
 
 	const op = "repository.ImageRepository.Delete"
 
-	_, err := r.db.Exec(query, id)
+	res, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return mapError(op, err)
 	}
-	return nil
+	return expectAffected(op, res)
+}
+
+// GetPerceptualHashes retrieves the perceptual hashes of the images in the project, or of the
+// user's personal images without a project, skipping images without one.
+func (r *ImageRepository) GetPerceptualHashes(ctx context.Context, userID, projectID string) ([]ImageHash, error) {
+	const op = "repository.ImageRepository.GetPerceptualHashes"
+
+	query := "SELECT id, phash FROM images WHERE project_id = $1 AND phash IS NOT NULL ORDER BY created_at"
+	scope := projectID
+	if projectID == "" {
+		query = "SELECT id, phash FROM images WHERE user_id = $1 AND project_id IS NULL AND phash IS NOT NULL ORDER BY created_at"
+		scope = userID
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, scope)
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	defer rows.Close()
+
+	var hashes []ImageHash
+	for rows.Next() {
+		var h ImageHash
+		var v int64
+		if err := rows.Scan(&h.ImageID, &v); err != nil {
+			return nil, mapError(op, err)
+		}
+		h.PerceptualHash = formatPHash(v)
+		hashes = append(hashes, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(op, err)
+	}
+	return hashes, nil
+}
+
+// GetPendingThumbnails retrieves up to limit uploaded images still waiting for thumbnails, oldest first.
+func (r *ImageRepository) GetPendingThumbnails(ctx context.Context, limit int) ([]*Image, error) {
+	query := "SELECT " + imageColumns + " FROM images WHERE thumbnail_status = $1 ORDER BY created_at LIMIT $2"
+
+	const op = "repository.ImageRepository.GetPendingThumbnails"
+
+	return r.query(ctx, op, query, ThumbnailPending, limit)
+}
+
+// SetThumbnails records the outcome of thumbnail generation for an image.
+func (r *ImageRepository) SetThumbnails(ctx context.Context, id, status string, thumbnails []Thumbnail) error {
+	query := "UPDATE images SET thumbnail_status = $1, thumbnails = $2 WHERE id = $3"
+
+	const op = "repository.ImageRepository.SetThumbnails"
+
+	// sent as text: lib/pq would encode a []byte as bytea
+	var encoded sql.NullString
+	if thumbnails != nil {
+		b, err := json.Marshal(thumbnails)
+		if err != nil {
+			return mapError(op, err)
+		}
+		encoded = sql.NullString{String: string(b), Valid: true}
+	}
+
+	res, err := r.db.ExecContext(ctx, query, status, encoded, id)
+	if err != nil {
+		return mapError(op, err)
+	}
+	return expectAffected(op, res)
+}
+
+// ResetThumbnails queues uploaded images for thumbnail generation and returns how many were queued.
+// With all unset only images without ready thumbnails are queued.
+func (r *ImageRepository) ResetThumbnails(ctx context.Context, all bool) (int64, error) {
+	query := "UPDATE images SET thumbnail_status = $1 WHERE object_key IS NOT NULL AND ($2 OR thumbnail_status IS DISTINCT FROM $3)"
+
+	const op = "repository.ImageRepository.ResetThumbnails"
+
+	res, err := r.db.ExecContext(ctx, query, ThumbnailPending, all, ThumbnailReady)
+	if err != nil {
+		return 0, mapError(op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, mapError(op, err)
+	}
+	return n, nil
+}
+
+func (r *ImageRepository) list(ctx context.Context, op string, opts ListOptions, conds []string, args []any) ([]*Image, string, error) {
+	tail, args, err := imageList.build(opts, conds, args)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	images, err := r.query(ctx, op, "SELECT "+imageColumns+" FROM images"+tail, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	images, next := paginate(images, opts, imageList.defaultSort, func(i *Image, sort string) (string, string) {
+		switch sort {
+		case "created_at":
+			return i.CreatedAt, i.ID
+		case "title":
+			return i.Title, i.ID
+		default:
+			return i.ID, i.ID
+		}
+	})
+	return images, next, nil
+}
+
+func (r *ImageRepository) query(ctx context.Context, op, query string, args ...any) ([]*Image, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	defer rows.Close()
+
+	var images []*Image
+	for rows.Next() {
+		image, err := scanImage(rows)
+		if err != nil {
+			return nil, mapError(op, err)
+		}
+		images = append(images, image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(op, err)
+	}
+	return images, nil
 }
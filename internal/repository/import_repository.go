@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+)
+
+// ImportBatch is a set of labels, images and annotations written together by Imports.Import.
+// Annotations refer to their image and label by pointer, so that new ones receive their
+// IDs on insert before the annotations are written.
+type ImportBatch struct {
+	// Labels are created; their IDs and creation times are set on insert
+	Labels []*ImportLabel
+	// Images are created; their IDs and creation times are set on insert
+	Images      []*Image
+	Annotations []*ImportAnnotation
+}
+
+// ImportLabel is a label to create, optionally nested under an existing or new label.
+type ImportLabel struct {
+	Label  *Label
+	Parent *Label
+}
+
+// ImportAnnotation is an annotation to create on an existing or new image. Label is nil
+// for unlabeled annotations and Mask holds the pixels of mask annotations.
+type ImportAnnotation struct {
+	Annotation *Annotation
+	Image      *Image
+	Label      *Label
+	Mask       *rle.RLE
+}
+
+// ImportRepository is a struct that writes imported datasets. Implements the Imports interface.
+type ImportRepository struct {
+	db *sql.DB
+}
+
+// Import writes the batch in a single transaction, so a failure leaves no part of it behind.
+func (r *ImportRepository) Import(ctx context.Context, batch *ImportBatch) error {
+	const op = "repository.ImportRepository.Import"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(op, err)
+	}
+	defer tx.Rollback()
+
+	for _, l := range batch.Labels {
+		if err := insertLabel(ctx, tx, l.Label); err != nil {
+			return mapError(op, err)
+		}
+	}
+	// parents are set once every new label has an ID
+	for _, l := range batch.Labels {
+		if l.Parent == nil {
+			continue
+		}
+		l.Label.ParentID = l.Parent.ID
+		if _, err := tx.ExecContext(ctx, `UPDATE labels SET parent_id = $1 WHERE id = $2`, l.Parent.ID, l.Label.ID); err != nil {
+			return mapError(op, err)
+		}
+	}
+
+	for _, image := range batch.Images {
+		if err := insertImage(ctx, tx, image); err != nil {
+			return mapError(op, err)
+		}
+	}
+
+	for _, a := range batch.Annotations {
+		a.Annotation.ImageID = a.Image.ID
+		if a.Label != nil {
+			a.Annotation.LabelID = a.Label.ID
+		}
+		if err := insertAnnotation(ctx, tx, a.Annotation); err != nil {
+			return mapError(op, err)
+		}
+		if a.Mask != nil {
+			if err := putMask(ctx, tx, a.Annotation.ID, a.Mask); err != nil {
+				return mapError(op, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
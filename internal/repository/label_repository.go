@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrLabelCycle is returned when a label would become its own ancestor.
+var ErrLabelCycle = errors.New("label cannot be nested under itself")
+
+// Label is a class of a project's taxonomy that annotations are tagged with - Model
+type Label struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	// ParentID is empty for top-level labels
+	ParentID    string `json:"parent_id,omitempty"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	// ArchivedAt is set once the label is retired; archived labels stay on existing annotations
+	ArchivedAt string `json:"archived_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// LabelRepository is a struct that provides methods to interact with the labels database table. Implements the Labels interface.
+type LabelRepository struct {
+	db *sql.DB
+}
+
+const labelColumns = `id, project_id, parent_id, name, color, description, archived_at, created_at`
+
+func scanLabel(row rowScanner) (*Label, error) {
+	var label Label
+	var parentID, archivedAt sql.NullString
+	if err := row.Scan(
+		&label.ID,
+		&label.ProjectID,
+		&parentID,
+		&label.Name,
+		&label.Color,
+		&label.Description,
+		&archivedAt,
+		&label.CreatedAt); err != nil {
+		return nil, err
+	}
+	label.ParentID = parentID.String
+	label.ArchivedAt = archivedAt.String
+	return &label, nil
+}
+
+// Create inserts a new label into the database. Returns a *DuplicateError for the name if an
+// active label of the project already uses it.
+func (r *LabelRepository) Create(ctx context.Context, label *Label) error {
+	const op = "repository.LabelRepository.Create"
+
+	if err := insertLabel(ctx, r.db, label); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
+
+func insertLabel(ctx context.Context, q querier, label *Label) error {
+	query := `INSERT INTO labels (project_id, parent_id, name, color, description) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+
+	return q.QueryRowContext(
+		ctx,
+		query,
+		label.ProjectID,
+		sql.NullString{String: label.ParentID, Valid: label.ParentID != ""},
+		label.Name,
+		label.Color,
+		label.Description,
+	).Scan(&label.ID, &label.CreatedAt)
+}
+
+// GetByID retrieves a label by its ID from the database. Returns ErrNotFound if the label does not exist.
+func (r *LabelRepository) GetByID(ctx context.Context, id string) (*Label, error) {
+	query := `SELECT ` + labelColumns + ` FROM labels WHERE id = $1`
+
+	const op = "repository.LabelRepository.GetByID"
+
+	label, err := scanLabel(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	return label, nil
+}
+
+// GetByProjectID retrieves the labels of a project ordered by name, including archived ones if asked.
+func (r *LabelRepository) GetByProjectID(ctx context.Context, projectID string, archived bool) ([]*Label, error) {
+	query := `SELECT ` + labelColumns + ` FROM labels WHERE project_id = $1 AND ($2 OR archived_at IS NULL) ORDER BY name, id`
+
+	const op = "repository.LabelRepository.GetByProjectID"
+
+	rows, err := r.db.QueryContext(ctx, query, projectID, archived)
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	defer rows.Close()
+
+	var labels []*Label
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, mapError(op, err)
+		}
+		labels = append(labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(op, err)
+	}
+	return labels, nil
+}
+
+// Update modifies the name, color, description and parent of a label. Returns ErrLabelCycle
+// if the new parent is the label itself or one of its descendants.
+func (r *LabelRepository) Update(ctx context.Context, label *Label) error {
+	const op = "repository.LabelRepository.Update"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(op, err)
+	}
+	defer tx.Rollback()
+
+	if label.ParentID != "" {
+		cycle, err := isAncestor(ctx, tx, label.ID, label.ParentID)
+		if err != nil {
+			return mapError(op, err)
+		}
+		if cycle {
+			return fmt.Errorf("%s: %w", op, ErrLabelCycle)
+		}
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE labels SET parent_id = $1, name = $2, color = $3, description = $4 WHERE id = $5`,
+		sql.NullString{String: label.ParentID, Valid: label.ParentID != ""},
+		label.Name,
+		label.Color,
+		label.Description,
+		label.ID,
+	)
+	if err != nil {
+		return mapError(op, err)
+	}
+	if err := expectAffected(op, res); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
+
+// SetArchived archives or restores a label. Restoring returns a *DuplicateError for the name
+// if an active label took it in the meantime.
+func (r *LabelRepository) SetArchived(ctx context.Context, label *Label, archived bool) error {
+	query := `UPDATE labels SET archived_at = CASE WHEN $1 THEN COALESCE(archived_at, CURRENT_TIMESTAMP) END
+		WHERE id = $2 RETURNING archived_at`
+
+	const op = "repository.LabelRepository.SetArchived"
+
+	var archivedAt sql.NullString
+	if err := r.db.QueryRowContext(ctx, query, archived, label.ID).Scan(&archivedAt); err != nil {
+		return mapError(op, err)
+	}
+	label.ArchivedAt = archivedAt.String
+	return nil
+}
+
+// Merge moves the annotations and child labels of source onto target and deletes source.
+// It returns the number of annotations relabeled, or ErrLabelCycle if target descends from source.
+func (r *LabelRepository) Merge(ctx context.Context, sourceID, targetID string) (int64, error) {
+	const op = "repository.LabelRepository.Merge"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, mapError(op, err)
+	}
+	defer tx.Rollback()
+
+	// lock both labels so concurrent merges and reparenting see a stable tree
+	var locked int
+	if err := tx.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM (SELECT id FROM labels WHERE id IN ($1, $2) ORDER BY id FOR UPDATE) l`,
+		sourceID,
+		targetID,
+	).Scan(&locked); err != nil {
+		return 0, mapError(op, err)
+	}
+	if locked != 2 {
+		return 0, fmt.Errorf("%s: %w", op, ErrNotFound)
+	}
+
+	cycle, err := isAncestor(ctx, tx, sourceID, targetID)
+	if err != nil {
+		return 0, mapError(op, err)
+	}
+	if cycle {
+		return 0, fmt.Errorf("%s: %w", op, ErrLabelCycle)
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE annotations SET label_id = $1 WHERE label_id = $2`, targetID, sourceID)
+	if err != nil {
+		return 0, mapError(op, err)
+	}
+	moved, err := res.RowsAffected()
+	if err != nil {
+		return 0, mapError(op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE labels SET parent_id = $1 WHERE parent_id = $2`, targetID, sourceID); err != nil {
+		return 0, mapError(op, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM labels WHERE id = $1`, sourceID); err != nil {
+		return 0, mapError(op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, mapError(op, err)
+	}
+	return moved, nil
+}
+
+// isAncestor reports whether ancestorID is labelID itself or one of its ancestors.
+func isAncestor(ctx context.Context, tx *sql.Tx, ancestorID, labelID string) (bool, error) {
+	query := `WITH RECURSIVE up (id, parent_id) AS (
+			SELECT id, parent_id FROM labels WHERE id = $1
+			UNION
+			SELECT l.id, l.parent_id FROM labels l JOIN up ON l.id = up.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM up WHERE id = $2)`
+
+	var found bool
+	err := tx.QueryRowContext(ctx, query, labelID, ancestorID).Scan(&found)
+	return found, err
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page size bounds for list queries.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// ErrInvalidListOptions is matched by every *ListOptionsError via errors.Is.
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// ListOptionsError is returned when a sort field, filter or cursor is not accepted by a list query.
+// Reason is meant for the client.
+type ListOptionsError struct {
+	Reason string
+}
+
+func (e *ListOptionsError) Error() string {
+	return "invalid list options: " + e.Reason
+}
+
+func (e *ListOptionsError) Is(target error) bool {
+	return target == ErrInvalidListOptions
+}
+
+// ListOptions controls paging, ordering and filtering of list queries.
+//
+// Paging is keyset based: Cursor is the opaque value returned with the previous
+// page and is only valid with the same Sort and Desc. Each repository documents
+// the fields it sorts and filters by.
+type ListOptions struct {
+	Cursor  string
+	Limit   int
+	Sort    string
+	Desc    bool
+	Filters map[string]string
+}
+
+// cursor is the position after the last row of a page: its sort value and ID,
+// which breaks ties between rows with the same sort value.
+type cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    string `json:"i"`
+}
+
+// column is a sortable or filterable SQL expression. Placeholders compared with it are cast to typ.
+type column struct {
+	expr string
+	typ  string
+}
+
+// filter matches rows whose column compares to the filter value with op.
+type filter struct {
+	column
+	op string
+}
+
+// listSpec describes what a list query may be sorted and filtered by.
+type listSpec struct {
+	id          column
+	sorts       map[string]column
+	defaultSort string
+	filters     map[string]filter
+}
+
+// build appends the filters, cursor position, ordering and limit of opts to the
+// conditions and arguments the caller already has, and returns the SQL tail to
+// append after FROM. One row more than the page size is requested so the caller
+// can tell whether there is a next page.
+func (s listSpec) build(opts ListOptions, conds []string, args []any) (string, []any, error) {
+	sortName := opts.Sort
+	if sortName == "" {
+		sortName = s.defaultSort
+	}
+	sort, ok := s.sorts[sortName]
+	if !ok {
+		return "", nil, &ListOptionsError{Reason: fmt.Sprintf("cannot sort by %q", sortName)}
+	}
+
+	arg := func(v any, typ string) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args)) + "::" + typ
+	}
+
+	for name, value := range opts.Filters {
+		f, ok := s.filters[name]
+		if !ok {
+			return "", nil, &ListOptionsError{Reason: fmt.Sprintf("cannot filter by %q", name)}
+		}
+		v, err := parseValue(value, f.typ)
+		if err != nil {
+			return "", nil, &ListOptionsError{Reason: fmt.Sprintf("invalid value for filter %q", name)}
+		}
+		conds = append(conds, f.expr+" "+f.op+" "+arg(v, f.typ))
+	}
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err == nil && (c.Sort != sortName || c.Desc != opts.Desc) {
+			err = errors.New("sort order changed")
+		}
+		if err == nil {
+			_, err = parseValue(c.ID, s.id.typ)
+		}
+		if err == nil && sortName != "id" {
+			_, err = parseValue(c.Value, sort.typ)
+		}
+		if err != nil {
+			return "", nil, &ListOptionsError{Reason: "cursor does not match the query"}
+		}
+		cmp := ">"
+		if opts.Desc {
+			cmp = "<"
+		}
+		if sortName == "id" {
+			conds = append(conds, s.id.expr+" "+cmp+" "+arg(c.ID, s.id.typ))
+		} else {
+			conds = append(conds, fmt.Sprintf("(%s, %s) %s (%s, %s)",
+				sort.expr, s.id.expr, cmp, arg(c.Value, sort.typ), arg(c.ID, s.id.typ)))
+		}
+	}
+
+	var b strings.Builder
+	if len(conds) > 0 {
+		b.WriteString(" WHERE " + strings.Join(conds, " AND "))
+	}
+
+	dir := " ASC"
+	if opts.Desc {
+		dir = " DESC"
+	}
+	b.WriteString(" ORDER BY " + sort.expr + dir)
+	if sortName != "id" {
+		b.WriteString(", " + s.id.expr + dir)
+	}
+	b.WriteString(" LIMIT " + strconv.Itoa(pageLimit(opts.Limit)+1))
+
+	return b.String(), args, nil
+}
+
+// paginate trims the extra row requested by build and returns the cursor of the
+// next page, or "" on the last page. key returns a row's sort value and ID.
+func paginate[T any](rows []T, opts ListOptions, defaultSort string, key func(row T, sort string) (string, string)) ([]T, string) {
+	limit := pageLimit(opts.Limit)
+	if len(rows) <= limit {
+		return rows, ""
+	}
+	rows = rows[:limit]
+
+	sort := opts.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	value, id := key(rows[limit-1], sort)
+	return rows, encodeCursor(cursor{Sort: sort, Desc: opts.Desc, Value: value, ID: id})
+}
+
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	return min(limit, MaxLimit)
+}
+
+// parseValue checks a filter value in Go so that a malformed value is reported
+// as such instead of failing the cast in Postgres.
+func parseValue(value, typ string) (any, error) {
+	switch typ {
+	case "int", "bigint":
+		return strconv.ParseInt(value, 10, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "timestamp":
+		return time.Parse(time.RFC3339Nano, value)
+	default:
+		return value, nil
+	}
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
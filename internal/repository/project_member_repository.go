@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrLastOwner is returned when a change would leave a project without an owner.
+var ErrLastOwner = errors.New("project must keep at least one owner")
+
+// ProjectMember is a user's membership in a project - Model
+type ProjectMember struct {
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ProjectMemberRepository is a struct that provides methods to interact with the project_members database table.
+// Implements the ProjectMembers interface.
+type ProjectMemberRepository struct {
+	db *sql.DB
+}
+
+// SetRole adds the user to the project or changes their role. Returns ErrLastOwner when demoting the only owner.
+func (r *ProjectMemberRepository) SetRole(ctx context.Context, projectID, userID, role string) error {
+	const op = "repository.ProjectMemberRepository.SetRole"
+
+	return r.withOwnerCheck(ctx, op, projectID, userID, role == ProjectOwner, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role`,
+			projectID,
+			userID,
+			role,
+		)
+		return err
+	})
+}
+
+// Remove revokes the user's membership. Returns ErrNotFound if they are not a member
+// and ErrLastOwner when removing the only owner.
+func (r *ProjectMemberRepository) Remove(ctx context.Context, projectID, userID string) error {
+	const op = "repository.ProjectMemberRepository.Remove"
+
+	return r.withOwnerCheck(ctx, op, projectID, userID, false, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM project_members WHERE project_id = $1 AND user_id = $2`, projectID, userID)
+		if err != nil {
+			return err
+		}
+		return expectAffected(op, res)
+	})
+}
+
+// GetRole returns the user's role in the project. Returns ErrNotFound if they are not a member.
+func (r *ProjectMemberRepository) GetRole(ctx context.Context, projectID, userID string) (string, error) {
+	query := `SELECT role FROM project_members WHERE project_id = $1 AND user_id = $2`
+
+	const op = "repository.ProjectMemberRepository.GetRole"
+
+	var role string
+	if err := r.db.QueryRowContext(ctx, query, projectID, userID).Scan(&role); err != nil {
+		return "", mapError(op, err)
+	}
+	return role, nil
+}
+
+// GetAll retrieves the members of a project, owners first.
+func (r *ProjectMemberRepository) GetAll(ctx context.Context, projectID string) ([]*ProjectMember, error) {
+	query := `SELECT m.user_id, u.username, m.role, m.created_at
+		FROM project_members m JOIN users u ON u.id = m.user_id
+		WHERE m.project_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, u.username`
+
+	const op = "repository.ProjectMemberRepository.GetAll"
+
+	rows, err := r.db.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	defer rows.Close()
+
+	var members []*ProjectMember
+	for rows.Next() {
+		var m ProjectMember
+		if err := rows.Scan(&m.UserID, &m.Username, &m.Role, &m.CreatedAt); err != nil {
+			return nil, mapError(op, err)
+		}
+		members = append(members, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(op, err)
+	}
+	return members, nil
+}
+
+// withOwnerCheck runs change in a transaction holding a lock on the project, so
+// concurrent membership changes cannot remove the last owner between the check
+// and the write. The check is skipped when the user keeps or gains ownership.
+func (r *ProjectMemberRepository) withOwnerCheck(ctx context.Context, op, projectID, userID string, staysOwner bool, change func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(op, err)
+	}
+	defer tx.Rollback()
+
+	var id string
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM projects WHERE id = $1 FOR UPDATE`, projectID).Scan(&id); err != nil {
+		return mapError(op, err)
+	}
+
+	if !staysOwner {
+		// true only when the user is the one and only owner
+		var lastOwner sql.NullBool
+		err := tx.QueryRowContext(
+			ctx,
+			`SELECT bool_and(user_id = $2) FROM project_members WHERE project_id = $1 AND role = $3`,
+			projectID,
+			userID,
+			ProjectOwner,
+		).Scan(&lastOwner)
+		if err != nil {
+			return mapError(op, err)
+		}
+		if lastOwner.Bool {
+			return fmt.Errorf("%s: %w", op, ErrLastOwner)
+		}
+	}
+
+	if err := change(tx); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return mapError(op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
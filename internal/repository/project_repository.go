@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Project roles, from most to least privileged. Owners manage the project and
+// its members, editors add images and annotate, viewers only read.
+const (
+	ProjectOwner  = "owner"
+	ProjectEditor = "editor"
+	ProjectViewer = "viewer"
+)
+
+// Project groups the images of a labeling job - Model
+type Project struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CreatedBy   string `json:"created_by"`
+	// Role is the requesting user's role, set when listing their projects
+	Role      string `json:"role,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ProjectRepository is a struct that provides methods to interact with the project database table. Implements the Projects interface.
+type ProjectRepository struct {
+	db *sql.DB
+}
+
+// Create inserts a new project and makes its creator the owner. It returns an error if the insertion fails.
+func (r *ProjectRepository) Create(ctx context.Context, project *Project) error {
+	query := `WITH p AS (
+			INSERT INTO projects (name, description, created_by) VALUES ($1, $2, $3) RETURNING id, created_at
+		), m AS (
+			INSERT INTO project_members (project_id, user_id, role) SELECT p.id, $3, '` + ProjectOwner + `' FROM p
+		)
+		SELECT id, created_at FROM p`
+
+	const op = "repository.ProjectRepository.Create"
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		project.Name,
+		project.Description,
+		project.CreatedBy,
+	).Scan(&project.ID, &project.CreatedAt)
+	if err != nil {
+		return mapError(op, err)
+	}
+	project.Role = ProjectOwner
+	return nil
+}
+
+// GetByID retrieves a project by its ID from the database. Returns ErrNotFound if the project does not exist.
+func (r *ProjectRepository) GetByID(ctx context.Context, id string) (*Project, error) {
+	query := `SELECT id, name, description, created_by, created_at FROM projects WHERE id = $1`
+
+	const op = "repository.ProjectRepository.GetByID"
+
+	var project Project
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&project.ID,
+		&project.Name,
+		&project.Description,
+		&project.CreatedBy,
+		&project.CreatedAt,
+	)
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	return &project, nil
+}
+
+// projectList sorts projects by id, created_at or name and filters them by the user's role.
+var projectList = listSpec{
+	id: column{"p.id", "int"},
+	sorts: map[string]column{
+		"id":         {"p.id", "int"},
+		"created_at": {"p.created_at", "timestamp"},
+		"name":       {"p.name", "text"},
+	},
+	defaultSort: "id",
+	filters: map[string]filter{
+		"role": {column{"m.role", "text"}, "="},
+	},
+}
+
+// GetAllForUser retrieves a page of the projects the user is a member of, with their role,
+// and the cursor of the next page.
+func (r *ProjectRepository) GetAllForUser(ctx context.Context, userID string, opts ListOptions) ([]*Project, string, error) {
+	const op = "repository.ProjectRepository.GetAllForUser"
+
+	tail, args, err := projectList.build(opts, []string{"m.user_id = $1"}, []any{userID})
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	query := `SELECT p.id, p.name, p.description, p.created_by, m.role, p.created_at
+		FROM projects p JOIN project_members m ON m.project_id = p.id` + tail
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", mapError(op, err)
+	}
+	defer rows.Close()
+
+	var projects []*Project
+	for rows.Next() {
+		var p Project
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.CreatedBy, &p.Role, &p.CreatedAt); err != nil {
+			return nil, "", mapError(op, err)
+		}
+		projects = append(projects, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", mapError(op, err)
+	}
+
+	projects, next := paginate(projects, opts, projectList.defaultSort, func(p *Project, sort string) (string, string) {
+		switch sort {
+		case "created_at":
+			return p.CreatedAt, p.ID
+		case "name":
+			return p.Name, p.ID
+		default:
+			return p.ID, p.ID
+		}
+	})
+	return projects, next, nil
+}
+
+// Update modifies the name and description of a project. It returns an error if the update fails.
+func (r *ProjectRepository) Update(ctx context.Context, project *Project) error {
+	query := `UPDATE projects SET name = $1, description = $2 WHERE id = $3`
+
+	const op = "repository.ProjectRepository.Update"
+
+	res, err := r.db.ExecContext(ctx, query, project.Name, project.Description, project.ID)
+	if err != nil {
+		return mapError(op, err)
+	}
+	return expectAffected(op, res)
+}
+
+// Delete removes a project together with its images and their annotations.
+func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM projects WHERE id = $1`
+
+	const op = "repository.ProjectRepository.Delete"
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return mapError(op, err)
+	}
+	return expectAffected(op, res)
+}
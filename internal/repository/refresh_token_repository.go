@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// RefreshToken represents a stored refresh token in the database - Model. Only the token hash is persisted.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt string
+}
+
+// Revoked reports whether the token has been revoked.
+func (t *RefreshToken) Revoked() bool {
+	return t.RevokedAt != nil
+}
+
+// Expired reports whether the token is past its expiry time.
+func (t *RefreshToken) Expired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// RefreshTokenRepository is a struct that provides methods to interact with the refresh_tokens database table. Implements the RefreshTokens interface.
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// Create inserts a new refresh token into the database. It returns an error if the insertion fails.
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3) RETURNING id, created_at`
+
+	const op = "repository.RefreshTokenRepository.Create"
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
+
+// GetByHash retrieves a refresh token by its hash. Returns ErrNotFound if the token does not exist.
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*RefreshToken, error) {
+	query := `SELECT id, user_id, token_hash, expires_at, revoked_at, created_at FROM refresh_tokens WHERE token_hash = $1`
+
+	const op = "repository.RefreshTokenRepository.GetByHash"
+
+	var token RefreshToken
+	var revokedAt sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, hash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&revokedAt,
+		&token.CreatedAt); err != nil {
+		return nil, mapError(op, err)
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
+
+// Revoke marks a refresh token as revoked. Returns false if the token was already revoked,
+// which lets callers detect concurrent reuse of the same token.
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+
+	const op = "repository.RefreshTokenRepository.Revoke"
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, mapError(op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, mapError(op, err)
+	}
+	return n > 0, nil
+}
+
+// RevokeAllForUser revokes every active refresh token belonging to the user.
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`
+
+	const op = "repository.RefreshTokenRepository.RevokeAllForUser"
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
@@ -1,47 +1,135 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+
 	_ "github.com/lib/pq" // Import the pq driver for PostgreSQL
 )
 
 // Repository is a struct that holds the database connection and repositories for different entities.
 type Repository struct {
-	Users       Users
-	Images      Images
-	Annotations Annotations
+	db *sql.DB
+
+	Users          Users
+	Images         Images
+	Annotations    Annotations
+	RefreshTokens  RefreshTokens
+	ImageMembers   ImageMembers
+	Roles          Roles
+	Projects       Projects
+	ProjectMembers ProjectMembers
+	Labels         Labels
+	Imports        Imports
 }
 
 type Users interface {
-	Create(user *User) error
-	GetAll() ([]*User, error)
-	GetByID(id string) (*User, error)
-	Update(user *User) error
-	Delete(id string) error
+	Create(ctx context.Context, user *User) error
+	GetAll(ctx context.Context, opts ListOptions) ([]*User, string, error)
+	GetByID(ctx context.Context, id string) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id string) error
 }
 
 type Images interface {
-	Create(image *Image) error
-	GetAll() ([]*Image, error)
-	GetByID(id string) (*Image, error)
-	Update(image *Image) error
-	Delete(id string) error
+	Create(ctx context.Context, image *Image) error
+	GetAll(ctx context.Context, opts ListOptions) ([]*Image, string, error)
+	GetAllVisibleTo(ctx context.Context, userID string, opts ListOptions) ([]*Image, string, error)
+	GetByID(ctx context.Context, id string) (*Image, error)
+	Update(ctx context.Context, image *Image) error
+	Delete(ctx context.Context, id string) error
+	GetByContentHash(ctx context.Context, userID, projectID, hash string) (*Image, error)
+	HasObjectKey(ctx context.Context, key string) (bool, error)
+	GetPerceptualHashes(ctx context.Context, userID, projectID string) ([]ImageHash, error)
+	GetPendingThumbnails(ctx context.Context, limit int) ([]*Image, error)
+	SetThumbnails(ctx context.Context, id, status string, thumbnails []Thumbnail) error
+	ResetThumbnails(ctx context.Context, all bool) (int64, error)
 }
 
 type Annotations interface {
-	Create(annotation *Annotation) error
-	GetAll() ([]*Annotation, error)
-	GetByID(id string) (*Annotation, error)
-	Update(annotation *Annotation) error
-	Delete(id string) error
+	Create(ctx context.Context, annotation *Annotation) error
+	GetAll(ctx context.Context, opts ListOptions) ([]*Annotation, string, error)
+	GetByImageID(ctx context.Context, imageID string, opts ListOptions) ([]*Annotation, string, error)
+	GetByImageIDs(ctx context.Context, imageIDs []string) ([]*Annotation, error)
+	GetByID(ctx context.Context, id string) (*Annotation, error)
+	Update(ctx context.Context, annotation *Annotation) error
+	CreateMask(ctx context.Context, annotation *Annotation, mask *rle.RLE) error
+	GetMask(ctx context.Context, annotationID string) (*rle.RLE, error)
+	GetMasks(ctx context.Context, annotationIDs []string) (map[string]*rle.RLE, error)
+	UpdateMask(ctx context.Context, annotation *Annotation, mask *rle.RLE) error
+	Review(ctx context.Context, annotation *Annotation) error
+	Delete(ctx context.Context, id string) error
+}
+
+type RefreshTokens interface {
+	Create(ctx context.Context, token *RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*RefreshToken, error)
+	Revoke(ctx context.Context, id string) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID string) error
+}
+
+type ImageMembers interface {
+	Add(ctx context.Context, imageID, userID string) error
+	Remove(ctx context.Context, imageID, userID string) error
+	IsMember(ctx context.Context, imageID, userID string) (bool, error)
+}
+
+type Roles interface {
+	GetAll(ctx context.Context) ([]*Role, error)
+	GetByUserID(ctx context.Context, userID string) ([]string, error)
+	GetPermissions(ctx context.Context, userID string) ([]string, error)
+	SetUserRoles(ctx context.Context, userID string, roles []string) error
+}
+
+type Projects interface {
+	Create(ctx context.Context, project *Project) error
+	GetByID(ctx context.Context, id string) (*Project, error)
+	GetAllForUser(ctx context.Context, userID string, opts ListOptions) ([]*Project, string, error)
+	Update(ctx context.Context, project *Project) error
+	Delete(ctx context.Context, id string) error
+}
+
+type Labels interface {
+	Create(ctx context.Context, label *Label) error
+	GetByID(ctx context.Context, id string) (*Label, error)
+	GetByProjectID(ctx context.Context, projectID string, archived bool) ([]*Label, error)
+	Update(ctx context.Context, label *Label) error
+	SetArchived(ctx context.Context, label *Label, archived bool) error
+	Merge(ctx context.Context, sourceID, targetID string) (int64, error)
+}
+
+type ProjectMembers interface {
+	SetRole(ctx context.Context, projectID, userID, role string) error
+	Remove(ctx context.Context, projectID, userID string) error
+	GetRole(ctx context.Context, projectID, userID string) (string, error)
+	GetAll(ctx context.Context, projectID string) ([]*ProjectMember, error)
+}
+
+type Imports interface {
+	Import(ctx context.Context, batch *ImportBatch) error
 }
 
 // New creates a new Repository instance from a PostgreSQL connection pool.
 func NewRepository(db *sql.DB) Repository {
 	return Repository{
-		Users:       &UserRepository{db: db},
-		Images:      &ImageRepository{db: db},
-		Annotations: &AnnotationRepository{db: db},
+		db:             db,
+		Users:          &UserRepository{db: db},
+		Images:         &ImageRepository{db: db},
+		Annotations:    &AnnotationRepository{db: db},
+		RefreshTokens:  &RefreshTokenRepository{db: db},
+		ImageMembers:   &ImageMemberRepository{db: db},
+		Roles:          &RoleRepository{db: db},
+		Projects:       &ProjectRepository{db: db},
+		ProjectMembers: &ProjectMemberRepository{db: db},
+		Labels:         &LabelRepository{db: db},
+		Imports:        &ImportRepository{db: db},
 	}
 }
+
+// Ping verifies the database is reachable.
+func (r Repository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Built-in roles seeded by the roles migration.
+const (
+	RoleAdmin     = "admin"
+	RoleReviewer  = "reviewer"
+	RoleAnnotator = "annotator"
+
+	// DefaultRole is granted to every newly registered user.
+	DefaultRole = RoleAnnotator
+)
+
+// Permissions checked by the API.
+const (
+	PermUsersRead         = "users:read"
+	PermUsersManage       = "users:manage"
+	PermAnnotationsWrite  = "annotations:write"
+	PermAnnotationsReview = "annotations:review"
+)
+
+// Role represents a role and the permissions it grants - Model
+type Role struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleRepository is a struct that provides methods to interact with the roles, permissions and user_roles tables. Implements the Roles interface.
+type RoleRepository struct {
+	db *sql.DB
+}
+
+// GetAll retrieves all roles with their permissions.
+func (r *RoleRepository) GetAll(ctx context.Context) ([]*Role, error) {
+	query := `SELECT r.id, r.name, COALESCE(array_agg(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		GROUP BY r.id, r.name
+		ORDER BY r.id`
+
+	const op = "repository.RoleRepository.GetAll"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	defer rows.Close()
+
+	var roles []*Role
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.ID, &role.Name, pq.Array(&role.Permissions)); err != nil {
+			return nil, mapError(op, err)
+		}
+		roles = append(roles, &role)
+	}
+	return roles, nil
+}
+
+// GetByUserID retrieves the names of the roles assigned to the user.
+func (r *RoleRepository) GetByUserID(ctx context.Context, userID string) ([]string, error) {
+	query := `SELECT r.name FROM roles r JOIN user_roles ur ON ur.role_id = r.id WHERE ur.user_id = $1 ORDER BY r.name`
+
+	const op = "repository.RoleRepository.GetByUserID"
+
+	return r.queryNames(ctx, op, query, userID)
+}
+
+// GetPermissions retrieves the union of permissions granted to the user by all their roles.
+func (r *RoleRepository) GetPermissions(ctx context.Context, userID string) ([]string, error) {
+	query := `SELECT DISTINCT p.name FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		JOIN user_roles ur ON ur.role_id = rp.role_id
+		WHERE ur.user_id = $1
+		ORDER BY p.name`
+
+	const op = "repository.RoleRepository.GetPermissions"
+
+	return r.queryNames(ctx, op, query, userID)
+}
+
+// SetUserRoles replaces the user's roles with the given ones. Unknown role names are rejected.
+func (r *RoleRepository) SetUserRoles(ctx context.Context, userID string, roles []string) error {
+	const op = "repository.RoleRepository.SetUserRoles"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(op, err)
+	}
+	defer tx.Rollback()
+
+	var known int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM roles WHERE name = ANY($1)`, pq.Array(roles)).Scan(&known); err != nil {
+		return mapError(op, err)
+	}
+	if known != len(uniqueStrings(roles)) {
+		return fmt.Errorf("%s: unknown role in %v", op, roles)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id = $1`, userID); err != nil {
+		return mapError(op, err)
+	}
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO user_roles (user_id, role_id) SELECT $1, id FROM roles WHERE name = ANY($2)`,
+		userID,
+		pq.Array(roles),
+	); err != nil {
+		return mapError(op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
+
+func (r *RoleRepository) queryNames(ctx context.Context, op, query string, args ...any) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, mapError(op, err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
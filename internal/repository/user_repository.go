@@ -1,17 +1,19 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
 
 // User represents a user in the database - Model
 type User struct {
-	ID        string `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	Password  string `json:"-"`
-	CreatedAt string `json:"created_at"`
+	ID        string   `json:"id"`
+	Username  string   `json:"username"`
+	Email     string   `json:"email"`
+	Password  string   `json:"-"`
+	Roles     []string `json:"roles,omitempty"`
+	CreatedAt string   `json:"created_at"`
 }
 
 // UserRepository is a struct that provides methods to interact with the user database table. Implements the Users interface.
@@ -19,13 +21,19 @@ type UserRepository struct {
 	db *sql.DB
 }
 
-// Create inserts a new user into the database. It returns an error if the insertion fails.
-func (r *UserRepository) Create(user *User) error {
-	query := `INSERT INTO users (username, email, password) VALUES ($1, $2, $3) Returning id, created_at`
+// Create inserts a new user into the database and grants them the default role. It returns an error if the insertion fails.
+func (r *UserRepository) Create(ctx context.Context, user *User) error {
+	query := `WITH u AS (
+			INSERT INTO users (username, email, password) VALUES ($1, $2, $3) RETURNING id, created_at
+		), ur AS (
+			INSERT INTO user_roles (user_id, role_id) SELECT u.id, roles.id FROM u, roles WHERE roles.name = '` + DefaultRole + `'
+		)
+		SELECT id, created_at FROM u`
 
 	const op = "repository.UserRepository.Create"
 
-	var err = r.db.QueryRow(
+	var err = r.db.QueryRowContext(
+		ctx,
 		query,
 		user.Username,
 		user.Email,
@@ -33,20 +41,40 @@ func (r *UserRepository) Create(user *User) error {
 	).Scan(&user.ID, &user.CreatedAt)
 
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return mapError(op, err)
 	}
 	return nil
 }
 
-// GetAll retrieves all users from the database.
-func (r *UserRepository) GetAll() ([]*User, error) {
-	query := `SELECT id, username, email, created_at FROM users`
+// userList sorts users by id, created_at, username or email and filters them by exact username or email.
+var userList = listSpec{
+	id: column{"id", "int"},
+	sorts: map[string]column{
+		"id":         {"id", "int"},
+		"created_at": {"created_at", "timestamp"},
+		"username":   {"username", "text"},
+		"email":      {"email", "text"},
+	},
+	defaultSort: "id",
+	filters: map[string]filter{
+		"username": {column{"username", "text"}, "="},
+		"email":    {column{"email", "text"}, "="},
+	},
+}
 
+// GetAll retrieves a page of users and the cursor of the next page, empty on the last page.
+func (r *UserRepository) GetAll(ctx context.Context, opts ListOptions) ([]*User, string, error) {
 	const op = "repository.UserRepository.GetAll"
 
-	rows, err := r.db.Query(query)
+	tail, args, err := userList.build(opts, nil, nil)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	query := `SELECT id, username, email, created_at FROM users` + tail
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", mapError(op, err)
 	}
 	defer rows.Close()
 
@@ -54,38 +82,68 @@ func (r *UserRepository) GetAll() ([]*User, error) {
 	for rows.Next() {
 		var user User
 		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt); err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+			return nil, "", mapError(op, err)
 		}
 		users = append(users, &user)
 	}
-	return users, nil
+	if err := rows.Err(); err != nil {
+		return nil, "", mapError(op, err)
+	}
+
+	users, next := paginate(users, opts, userList.defaultSort, func(u *User, sort string) (string, string) {
+		switch sort {
+		case "created_at":
+			return u.CreatedAt, u.ID
+		case "username":
+			return u.Username, u.ID
+		case "email":
+			return u.Email, u.ID
+		default:
+			return u.ID, u.ID
+		}
+	})
+	return users, next, nil
 }
 
-// GetByID retrieves a user by their ID from the database. Does not return an error if the user is not found.
-func (r *UserRepository) GetByID(id string) (*User, error) {
+// GetByID retrieves a user by their ID from the database. Returns ErrNotFound if the user does not exist.
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*User, error) {
 	query := `SELECT id, username, email, created_at FROM users WHERE id = $1`
 
 	const op = "repository.UserRepository.GetByID"
 
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRowContext(ctx, query, id)
 
 	var user User
 	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, mapError(op, err)
+	}
+	return &user, nil
+}
+
+// GetByEmail retrieves a user by their email, including the password hash, for credential checks.
+// Returns ErrNotFound if the user does not exist.
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	query := `SELECT id, username, email, password, created_at FROM users WHERE email = $1`
+
+	const op = "repository.UserRepository.GetByEmail"
+
+	row := r.db.QueryRowContext(ctx, query, email)
+
+	var user User
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt); err != nil {
+		return nil, mapError(op, err)
 	}
 	return &user, nil
 }
 
 // Update modifies an existing user in the database. It returns an error if the update fails.
-func (r *UserRepository) Update(user *User) error {
+func (r *UserRepository) Update(ctx context.Context, user *User) error {
 	query := `UPDATE users SET username = $1, email = $2, password = $3 WHERE id = $4`
 
 	const op = "repository.UserRepository.Update"
 
-	_, err := r.db.Exec(
+	res, err := r.db.ExecContext(
+		ctx,
 		query,
 		user.Username,
 		user.Email,
@@ -94,19 +152,19 @@ func (r *UserRepository) Update(user *User) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return mapError(op, err)
 	}
-	return nil
+	return expectAffected(op, res)
 }
 
-func (r *UserRepository) Delete(id string) error {
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM users WHERE id = $1`
 
 	const op = "repository.UserRepository.Delete"
 
-	_, err := r.db.Exec(query, id)
+	res, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return mapError(op, err)
 	}
-	return nil
+	return expectAffected(op, res)
 }
@@ -0,0 +1,265 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+var (
+	// ErrNotFound is returned when the resource does not exist or the user may not know it exists.
+	ErrNotFound = errors.New("not found")
+	// ErrForbidden is returned when the user can see the resource but may not modify it.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Policy decides what an authenticated user may do with projects, images and annotations.
+//
+// Public images are readable by everyone. Access to a project image otherwise follows
+// only the user's role in the project, whoever uploaded it: members read it, owners and
+// editors annotate it, and owners, or editors who uploaded it, manage it. Personal images
+// are readable by their owner and by members they were shared with, managed by their
+// owner and annotated by whoever can read them. Annotations are editable by project owners
+// and editors, and on personal images by their author and by the owner of the image.
+// A resource the user cannot read is reported as not found, never as forbidden.
+type Policy struct {
+	images         repository.Images
+	annotations    repository.Annotations
+	members        repository.ImageMembers
+	projects       repository.Projects
+	projectMembers repository.ProjectMembers
+}
+
+// NewPolicy creates a new access policy backed by the given repositories.
+func NewPolicy(
+	images repository.Images,
+	annotations repository.Annotations,
+	members repository.ImageMembers,
+	projects repository.Projects,
+	projectMembers repository.ProjectMembers,
+) *Policy {
+	return &Policy{
+		images:         images,
+		annotations:    annotations,
+		members:        members,
+		projects:       projects,
+		projectMembers: projectMembers,
+	}
+}
+
+// ViewProject loads the project if the user is a member of it.
+func (p *Policy) ViewProject(ctx context.Context, user *repository.User, projectID string) (*repository.Project, error) {
+	const op = "access.Policy.ViewProject"
+
+	project, err := p.projects.GetByID(ctx, projectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	role, err := p.projectRole(ctx, user, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if role == "" {
+		return nil, ErrNotFound
+	}
+	project.Role = role
+	return project, nil
+}
+
+// EditProject loads the project if the user may add images and annotations to it,
+// i.e. is one of its owners or editors.
+func (p *Policy) EditProject(ctx context.Context, user *repository.User, projectID string) (*repository.Project, error) {
+	project, err := p.ViewProject(ctx, user, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.Role == repository.ProjectViewer {
+		return nil, ErrForbidden
+	}
+	return project, nil
+}
+
+// ManageProject loads the project if the user owns it.
+func (p *Policy) ManageProject(ctx context.Context, user *repository.User, projectID string) (*repository.Project, error) {
+	project, err := p.ViewProject(ctx, user, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.Role != repository.ProjectOwner {
+		return nil, ErrForbidden
+	}
+	return project, nil
+}
+
+// ViewImage loads the image if the user may read it.
+func (p *Policy) ViewImage(ctx context.Context, user *repository.User, imageID string) (*repository.Image, error) {
+	const op = "access.Policy.ViewImage"
+
+	image, err := p.images.GetByID(ctx, imageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ok, err := p.canView(ctx, user, image)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return image, nil
+}
+
+// ManageImage loads the image if the user may delete, move or share it: the owner of a
+// personal image, or an owner of the image's project or an editor who uploaded it.
+func (p *Policy) ManageImage(ctx context.Context, user *repository.User, imageID string) (*repository.Image, error) {
+	const op = "access.Policy.ManageImage"
+
+	image, err := p.ViewImage(ctx, user, imageID)
+	if err != nil {
+		return nil, err
+	}
+	if image.ProjectID == "" {
+		if image.UserID != user.ID {
+			return nil, ErrForbidden
+		}
+		return image, nil
+	}
+
+	role, err := p.imageProjectRole(ctx, user, image)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if role != repository.ProjectOwner && (role != repository.ProjectEditor || image.UserID != user.ID) {
+		return nil, ErrForbidden
+	}
+	return image, nil
+}
+
+// AnnotateImage loads the image if the user may add annotations to it. Images in a
+// project take owners and editors of the project only, so viewers stay read-only.
+func (p *Policy) AnnotateImage(ctx context.Context, user *repository.User, imageID string) (*repository.Image, error) {
+	const op = "access.Policy.AnnotateImage"
+
+	image, err := p.ViewImage(ctx, user, imageID)
+	if err != nil {
+		return nil, err
+	}
+	if image.ProjectID == "" {
+		return image, nil
+	}
+
+	role, err := p.imageProjectRole(ctx, user, image)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if role != repository.ProjectOwner && role != repository.ProjectEditor {
+		return nil, ErrForbidden
+	}
+	return image, nil
+}
+
+// ViewAnnotation loads the annotation and its image if the user may read the image.
+func (p *Policy) ViewAnnotation(ctx context.Context, user *repository.User, annotationID string) (*repository.Annotation, *repository.Image, error) {
+	const op = "access.Policy.ViewAnnotation"
+
+	annotation, err := p.annotations.GetByID(ctx, annotationID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	image, err := p.ViewImage(ctx, user, annotation.ImageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return annotation, image, nil
+}
+
+// EditAnnotation loads the annotation and its image if the user is an owner or editor of
+// the image's project or, on a personal image, authored the annotation or owns the image.
+func (p *Policy) EditAnnotation(ctx context.Context, user *repository.User, annotationID string) (*repository.Annotation, *repository.Image, error) {
+	const op = "access.Policy.EditAnnotation"
+
+	annotation, image, err := p.ViewAnnotation(ctx, user, annotationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if image.ProjectID == "" {
+		if annotation.UserID != user.ID && image.UserID != user.ID {
+			return nil, nil, ErrForbidden
+		}
+		return annotation, image, nil
+	}
+
+	role, err := p.imageProjectRole(ctx, user, image)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if role != repository.ProjectOwner && role != repository.ProjectEditor {
+		return nil, nil, ErrForbidden
+	}
+	return annotation, image, nil
+}
+
+func (p *Policy) canView(ctx context.Context, user *repository.User, image *repository.Image) (bool, error) {
+	if image.Visibility {
+		return true, nil
+	}
+	if image.ProjectID != "" {
+		role, err := p.imageProjectRole(ctx, user, image)
+		return role != "", err
+	}
+	if image.UserID == user.ID {
+		return true, nil
+	}
+	return p.members.IsMember(ctx, image.ID, user.ID)
+}
+
+// imageProjectRole returns the user's role in the image's project, or "" if the image
+// has no project or the user is not a member of it.
+func (p *Policy) imageProjectRole(ctx context.Context, user *repository.User, image *repository.Image) (string, error) {
+	if image.ProjectID == "" {
+		return "", nil
+	}
+	return p.projectRole(ctx, user, image.ProjectID)
+}
+
+// projectRole returns the user's role in the project, or "" if they are not a member.
+func (p *Policy) projectRole(ctx context.Context, user *repository.User, projectID string) (string, error) {
+	role, err := p.projectMembers.GetRole(ctx, projectID, user.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", nil
+	}
+	return role, err
+}
+
+// RenderError writes the response for a failed access check on the named resource, e.g. "Image".
+func RenderError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, resource string) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, repository.ErrNotFound):
+		log.Info("Resource not found or not visible", slog.String("resource", resource))
+		resp.RenderError(w, r, resp.NotFound(resource+" not found"))
+	case errors.Is(err, ErrForbidden):
+		log.Info("Access denied", slog.String("resource", resource))
+		resp.RenderError(w, r, resp.Forbidden("Not allowed to modify this "+strings.ToLower(resource)))
+	default:
+		log.Error("Failed to check access", "error", err, slog.String("resource", resource))
+		resp.RenderError(w, r, resp.Internal("Failed to get "+strings.ToLower(resource)))
+	}
+}
@@ -0,0 +1,51 @@
+package annotation
+
+import (
+	"fmt"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// boundsTolerance absorbs floating point error in rotated corners, in pixels.
+const boundsTolerance = 1e-6
+
+// checkBounds reports the box edges that fall outside the image. Rotated boxes must
+// keep all four corners inside. Images whose dimensions are unknown accept any box.
+func checkBounds(annotation *repository.Annotation, image *repository.Image) []resp.FieldError {
+	if image.Metadata == nil {
+		return nil
+	}
+	width, height := float64(image.Metadata.Width), float64(image.Metadata.Height)
+
+	if g := annotation.Geometry; g != nil && g.Type == geometry.Box && g.Angle != 0 {
+		for _, c := range geometry.Corners(annotation.X, annotation.Y, annotation.Width, annotation.Height, g.Angle) {
+			if c[0] < -boundsTolerance || c[1] < -boundsTolerance || c[0] > width+boundsTolerance || c[1] > height+boundsTolerance {
+				return []resp.FieldError{{
+					Field:   "geometry.angle",
+					Code:    "out_of_bounds",
+					Message: fmt.Sprintf("Rotated box exceeds the %dx%d px image", image.Metadata.Width, image.Metadata.Height),
+				}}
+			}
+		}
+		return nil
+	}
+
+	var details []resp.FieldError
+	if annotation.X+annotation.Width > width {
+		details = append(details, resp.FieldError{
+			Field:   "width",
+			Code:    "out_of_bounds",
+			Message: fmt.Sprintf("Box exceeds the image width of %d px", image.Metadata.Width),
+		})
+	}
+	if annotation.Y+annotation.Height > height {
+		details = append(details, resp.FieldError{
+			Field:   "height",
+			Code:    "out_of_bounds",
+			Message: fmt.Sprintf("Box exceeds the image height of %d px", image.Metadata.Height),
+		})
+	}
+	return details
+}
@@ -0,0 +1,54 @@
+package annotation
+
+import (
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// Coordinate modes selected with the "coords" query parameter. Pixel coordinates are
+// the default; normalized coordinates are fractions of the image width and height.
+const (
+	coordsPixel      = "pixel"
+	coordsNormalized = "normalized"
+)
+
+// normalizedCoords reports whether the request reads and writes normalized coordinates.
+// It writes a 400 response and returns ok=false if the mode is unknown or the image has
+// no stored dimensions to normalize against.
+func normalizedCoords(w http.ResponseWriter, r *http.Request, image *repository.Image) (normalized, ok bool) {
+	switch r.URL.Query().Get("coords") {
+	case "", coordsPixel:
+		return false, true
+	case coordsNormalized:
+		if image.Metadata == nil || image.Metadata.Width == 0 || image.Metadata.Height == 0 {
+			resp.RenderError(w, r, resp.BadRequest("Normalized coordinates need the image dimensions, which are unknown for this image"))
+			return false, false
+		}
+		return true, true
+	default:
+		resp.RenderError(w, r, resp.BadRequest("Query parameter 'coords' must be one of [pixel normalized]"))
+		return false, false
+	}
+}
+
+// normalize converts the annotation from pixels to fractions of the image size.
+func normalize(annotation *repository.Annotation, image *repository.Image) {
+	scale(annotation, 1/float64(image.Metadata.Width), 1/float64(image.Metadata.Height))
+}
+
+// denormalize converts the annotation from fractions of the image size to pixels.
+func denormalize(annotation *repository.Annotation, image *repository.Image) {
+	scale(annotation, float64(image.Metadata.Width), float64(image.Metadata.Height))
+}
+
+func scale(annotation *repository.Annotation, sx, sy float64) {
+	annotation.X *= sx
+	annotation.Width *= sx
+	annotation.Y *= sy
+	annotation.Height *= sy
+	if annotation.Geometry != nil {
+		annotation.Geometry.Scale(sx, sy)
+	}
+}
@@ -0,0 +1,147 @@
+package annotation
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateAnnotationRequest struct {
+	LabelID string  `json:"label_id" validate:"omitempty,numeric"`
+	X       float64 `json:"x" validate:"gte=0"`
+	Y       float64 `json:"y" validate:"gte=0"`
+	Width   float64 `json:"width" validate:"gte=0"`
+	Height  float64 `json:"height" validate:"gte=0"`
+	// Geometry defaults to a box given by X, Y, Width and Height
+	Geometry *geometry.Geometry `json:"geometry"`
+	// Attributes are free-form flags such as "difficult" or "truncated"
+	Attributes map[string]any `json:"attributes" validate:"max=50"`
+	Comment    string         `json:"comment" validate:"max=2000"`
+}
+
+type CreateAnnotationResponse struct {
+	Response   resp.Response          `json:"response"`
+	Annotation *repository.Annotation `json:"annotation"`
+}
+
+// CreateAnnotationHandler adds an annotation to an image the user may annotate. Shapes other
+// than boxes derive x, y, width and height from their points. The optional label must be
+// an active label of the image's project. With ?coords=normalized the coordinates are
+// read and returned as fractions of the image size. Masks are created with CreateMaskHandler.
+func CreateAnnotationHandler(annotations repository.Annotations, labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.annotation.CreateAnnotationHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		imageID := chi.URLParam(r, "imageID")
+
+		var req CreateAnnotationRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+		log.Info("Request body decoded", slog.Any("request", req))
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		image, err := policy.AnnotateImage(r.Context(), mwAuth.UserFromContext(r.Context()), imageID)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", imageID)), err, "Image")
+			return
+		}
+
+		normalized, ok := normalizedCoords(w, r, image)
+		if !ok {
+			return
+		}
+
+		if req.Geometry != nil && req.Geometry.Type == geometry.Mask {
+			resp.RenderError(w, r, resp.Invalid(maskGeometryError))
+			return
+		}
+
+		annotation := &repository.Annotation{
+			ImageID:    image.ID,
+			UserID:     mwAuth.UserFromContext(r.Context()).ID,
+			LabelID:    req.LabelID,
+			X:          req.X,
+			Y:          req.Y,
+			Width:      req.Width,
+			Height:     req.Height,
+			Geometry:   req.Geometry,
+			Attributes: req.Attributes,
+			Comment:    req.Comment,
+		}
+
+		if details := applyGeometry(annotation); details != nil {
+			resp.RenderError(w, r, resp.Invalid(details...))
+			return
+		}
+		if normalized {
+			denormalize(annotation, image)
+		}
+		if details := checkBounds(annotation, image); details != nil {
+			resp.RenderError(w, r, resp.Invalid(details...))
+			return
+		}
+
+		invalid, err := checkLabel(r.Context(), labels, annotation.LabelID, "", image)
+		if err != nil {
+			log.Error("Failed to check label", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to create annotation"))
+			return
+		}
+		if invalid != nil {
+			resp.RenderError(w, r, resp.Invalid(*invalid))
+			return
+		}
+
+		err = annotations.Create(r.Context(), annotation)
+		if errors.Is(err, repository.ErrForeignKey) {
+			// the image was deleted after the access check
+			resp.RenderError(w, r, resp.NotFound("Image not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to create annotation", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to create annotation"))
+			return
+		}
+
+		log.Info(
+			"Annotation created successfully",
+			slog.String("annotation_id", annotation.ID),
+			slog.String("image_id", annotation.ImageID),
+		)
+
+		if normalized {
+			normalize(annotation, image)
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateAnnotationResponse{
+			Response:   resp.OK(),
+			Annotation: annotation,
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package annotation
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// CreateMaskHandler adds a mask annotation to an image the user may annotate. The mask is sent as
+// COCO RLE in a JSON body or as a PNG "mask" part of a multipart form, of at most maxSize bytes.
+// Its area and bounding box are computed from the pixels. With ?coords=normalized the
+// returned bounding box is in fractions of the image size.
+func CreateMaskHandler(annotations repository.Annotations, labels repository.Labels, maxSize int64, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.annotation.CreateMaskHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		imageID := chi.URLParam(r, "imageID")
+
+		image, err := policy.AnnotateImage(r.Context(), mwAuth.UserFromContext(r.Context()), imageID)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", imageID)), err, "Image")
+			return
+		}
+
+		normalized, ok := normalizedCoords(w, r, image)
+		if !ok {
+			return
+		}
+
+		req, ok := decodeMask(w, r, log, maxSize, image)
+		if !ok {
+			return
+		}
+
+		invalid, err := checkLabel(r.Context(), labels, req.LabelID, "", image)
+		if err != nil {
+			log.Error("Failed to check label", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to create annotation"))
+			return
+		}
+		if invalid != nil {
+			resp.RenderError(w, r, resp.Invalid(*invalid))
+			return
+		}
+
+		annotation := &repository.Annotation{
+			ImageID: image.ID,
+			UserID:  mwAuth.UserFromContext(r.Context()).ID,
+			LabelID: req.LabelID,
+			Comment: req.Comment,
+		}
+
+		err = annotations.CreateMask(r.Context(), annotation, req.RLE)
+		if errors.Is(err, repository.ErrForeignKey) {
+			// the image was deleted after the access check
+			resp.RenderError(w, r, resp.NotFound("Image not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to create mask annotation", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to create annotation"))
+			return
+		}
+
+		log.Info(
+			"Mask annotation created successfully",
+			slog.String("annotation_id", annotation.ID),
+			slog.String("image_id", annotation.ImageID),
+			slog.Int("area", annotation.Geometry.Area),
+		)
+
+		if normalized {
+			normalize(annotation, image)
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateAnnotationResponse{
+			Response:   resp.OK(),
+			Annotation: annotation,
+		})
+	}
+}
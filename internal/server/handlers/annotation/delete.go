@@ -0,0 +1,48 @@
+package annotation
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+func DeleteAnnotationHandler(annotations repository.Annotations, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.annotation.DeleteAnnotationHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "annotationID")
+
+		if _, _, err := policy.EditAnnotation(r.Context(), mwAuth.UserFromContext(r.Context()), id); err != nil {
+			access.RenderError(w, r, log.With(slog.String("annotation_id", id)), err, "Annotation")
+			return
+		}
+
+		err := annotations.Delete(r.Context(), id)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Annotation not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to delete annotation", "error", err, slog.String("annotation_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to delete annotation"))
+			return
+		}
+
+		log.Info("Annotation deleted successfully", slog.String("annotation_id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
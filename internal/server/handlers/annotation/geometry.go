@@ -0,0 +1,37 @@
+package annotation
+
+import (
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// applyGeometry validates the annotation's shape. Boxes need a non-empty rectangle;
+// other shapes overwrite the rectangle with their bounding box.
+func applyGeometry(annotation *repository.Annotation) []resp.FieldError {
+	if annotation.Geometry == nil {
+		annotation.Geometry = &geometry.Geometry{Type: geometry.Box}
+	}
+	if err := annotation.Geometry.Validate(); err != nil {
+		return []resp.FieldError{{
+			Field:   "geometry",
+			Code:    "invalid_geometry",
+			Message: "Invalid geometry: " + err.Error(),
+		}}
+	}
+
+	x, y, width, height, ok := annotation.Geometry.Bounds()
+	if ok {
+		annotation.X, annotation.Y, annotation.Width, annotation.Height = x, y, width, height
+		return nil
+	}
+
+	var details []resp.FieldError
+	if annotation.Width <= 0 {
+		details = append(details, resp.FieldError{Field: "width", Code: "gt", Message: "Field 'width' must be greater than 0"})
+	}
+	if annotation.Height <= 0 {
+		details = append(details, resp.FieldError{Field: "height", Code: "gt", Message: "Field 'height' must be greater than 0"})
+	}
+	return details
+}
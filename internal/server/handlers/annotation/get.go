@@ -0,0 +1,53 @@
+package annotation
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type GetAnnotationResponse struct {
+	Response   resp.Response          `json:"response"`
+	Annotation *repository.Annotation `json:"annotation"`
+}
+
+// GetAnnotationHandler returns an annotation of an image the user can see, in pixels or,
+// with ?coords=normalized, in fractions of the image size.
+func GetAnnotationHandler(policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.annotation.GetAnnotationHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "annotationID")
+
+		annotation, image, err := policy.ViewAnnotation(r.Context(), mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("annotation_id", id)), err, "Annotation")
+			return
+		}
+
+		normalized, ok := normalizedCoords(w, r, image)
+		if !ok {
+			return
+		}
+		if normalized {
+			normalize(annotation, image)
+		}
+
+		render.JSON(w, r, GetAnnotationResponse{
+			Response:   resp.OK(),
+			Annotation: annotation,
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package annotation
+
+import (
+	"errors"
+	"image/png"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Mask formats selected with the "format" query parameter.
+const (
+	maskRLE = "rle"
+	maskPNG = "png"
+)
+
+type GetMaskResponse struct {
+	Response resp.Response `json:"response"`
+	Mask     *rle.RLE      `json:"mask"`
+}
+
+// GetMaskHandler returns the pixels of a mask annotation the user can see, as COCO RLE
+// in JSON or, with ?format=png, as a grayscale PNG with the foreground in white.
+func GetMaskHandler(annotations repository.Annotations, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.annotation.GetMaskHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "annotationID")
+
+		format := r.URL.Query().Get("format")
+		switch format {
+		case "":
+			format = maskRLE
+		case maskRLE, maskPNG:
+		default:
+			resp.RenderError(w, r, resp.BadRequest("Query parameter 'format' must be one of [rle png]"))
+			return
+		}
+
+		annotation, _, err := policy.ViewAnnotation(r.Context(), mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("annotation_id", id)), err, "Annotation")
+			return
+		}
+		if annotation.Geometry.Type != geometry.Mask {
+			resp.RenderError(w, r, resp.NotFound("Annotation has no mask"))
+			return
+		}
+
+		mask, err := annotations.GetMask(r.Context(), id)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Annotation has no mask"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get mask", "error", err, slog.String("annotation_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to get mask"))
+			return
+		}
+
+		if format == maskPNG {
+			w.Header().Set("Content-Type", "image/png")
+			if err := png.Encode(w, mask.Image()); err != nil {
+				// the status line is already sent, so the client sees a truncated image
+				log.Error("Failed to write mask", "error", err, slog.String("annotation_id", id))
+			}
+			return
+		}
+
+		render.JSON(w, r, GetMaskResponse{
+			Response: resp.OK(),
+			Mask:     mask,
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package annotation
+
+import (
+	"context"
+	"errors"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// checkLabel verifies that the label belongs to the taxonomy of the image's project and
+// is not archived. Images outside a project have no taxonomy and cannot be labeled.
+// keep is the annotation's current label, which may stay even once archived.
+func checkLabel(ctx context.Context, labels repository.Labels, labelID, keep string, image *repository.Image) (*resp.FieldError, error) {
+	if labelID == "" || labelID == keep {
+		return nil, nil
+	}
+	if image.ProjectID == "" {
+		return &resp.FieldError{
+			Field:   "label_id",
+			Code:    "invalid_label",
+			Message: "Images outside a project cannot be labeled",
+		}, nil
+	}
+
+	label, err := labels.GetByID(ctx, labelID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil || label.ProjectID != image.ProjectID {
+		return &resp.FieldError{
+			Field:   "label_id",
+			Code:    "invalid_label",
+			Message: "Label is not part of the project's taxonomy",
+		}, nil
+	}
+	if label.ArchivedAt != "" {
+		return &resp.FieldError{
+			Field:   "label_id",
+			Code:    "archived_label",
+			Message: "Label " + label.Name + " is archived",
+		}, nil
+	}
+	return nil, nil
+}
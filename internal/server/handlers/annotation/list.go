@@ -0,0 +1,78 @@
+package annotation
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	"github.com/Agero19/AnnotateX-api/internal/server/listquery"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type ListAnnotationsResponse struct {
+	Response    resp.Response            `json:"response"`
+	Annotations []*repository.Annotation `json:"annotations"`
+	NextCursor  string                   `json:"next_cursor,omitempty"`
+}
+
+// ListAnnotationsHandler returns a page of the annotations attached to the image in the URL.
+// See listquery.Parse for the paging parameters; annotations can be filtered by status, user_id, label_id and geometry type.
+// With ?coords=normalized coordinates are returned as fractions of the image size.
+func ListAnnotationsHandler(annotations repository.Annotations, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.annotation.ListAnnotationsHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		imageID := chi.URLParam(r, "imageID")
+
+		image, err := policy.ViewImage(r.Context(), mwAuth.UserFromContext(r.Context()), imageID)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", imageID)), err, "Image")
+			return
+		}
+
+		normalized, ok := normalizedCoords(w, r, image)
+		if !ok {
+			return
+		}
+
+		opts, err := listquery.Parse(r, "status", "user_id", "label_id", "type")
+		if err != nil {
+			resp.RenderError(w, r, resp.BadRequest(err.Error()))
+			return
+		}
+
+		list, next, err := annotations.GetByImageID(r.Context(), image.ID, opts)
+		if listquery.RenderError(w, r, err) {
+			return
+		}
+		if err != nil {
+			log.Error("Failed to list annotations", "error", err, slog.String("image_id", imageID))
+			resp.RenderError(w, r, resp.Internal("Failed to list annotations"))
+			return
+		}
+		if list == nil {
+			list = []*repository.Annotation{}
+		}
+		if normalized {
+			for _, annotation := range list {
+				normalize(annotation, image)
+			}
+		}
+
+		render.JSON(w, r, ListAnnotationsResponse{
+			Response:    resp.OK(),
+			Annotations: list,
+			NextCursor:  next,
+		})
+	}
+}
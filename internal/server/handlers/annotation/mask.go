@@ -0,0 +1,161 @@
+package annotation
+
+import (
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// maskMemory is how much of a multipart mask upload is kept in memory before spilling to temporary files.
+const maskMemory = 8 << 20
+
+// maskGeometryError is reported when a mask would be created or reshaped through the
+// JSON geometry, which cannot carry its pixels.
+var maskGeometryError = resp.FieldError{
+	Field:   "geometry",
+	Code:    "invalid_geometry",
+	Message: "Masks are uploaded through the mask endpoints",
+}
+
+// MaskRequest is a mask given either as a JSON body with COCO RLE, or as a multipart form
+// with a PNG "mask" part and the other fields as form values.
+type MaskRequest struct {
+	LabelID string   `json:"label_id" validate:"omitempty,numeric"`
+	Comment string   `json:"comment" validate:"max=2000"`
+	RLE     *rle.RLE `json:"rle"`
+}
+
+// decodeMask reads and validates a mask request. PNG masks are encoded to RLE, with
+// every pixel that is neither black nor transparent in the foreground. It writes the
+// error response and returns ok=false if the request is unusable.
+func decodeMask(w http.ResponseWriter, r *http.Request, log *slog.Logger, maxSize int64, image *repository.Image) (req MaskRequest, ok bool) {
+	// leave room for the other fields on top of the mask itself
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "multipart/form-data" {
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			var maxBytes *http.MaxBytesError
+			if errors.As(err, &maxBytes) {
+				resp.RenderError(w, r, maskTooLarge(maxSize))
+				return req, false
+			}
+			if errors.Is(err, rle.ErrInvalid) {
+				resp.RenderError(w, r, resp.Invalid(resp.FieldError{Field: "rle", Code: "invalid_rle", Message: "Invalid RLE: " + err.Error()}))
+				return req, false
+			}
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return req, false
+		}
+	} else if !decodeMaskForm(w, r, log, maxSize, &req) {
+		return req, false
+	}
+
+	if err := validate.Struct(req); err != nil {
+		log.Error("Invalid request payload", "error", err)
+		resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+		return req, false
+	}
+	if details := checkMask(req.RLE, image); details != nil {
+		resp.RenderError(w, r, resp.Invalid(*details))
+		return req, false
+	}
+	return req, true
+}
+
+// decodeMaskForm reads a multipart mask upload into req.
+func decodeMaskForm(w http.ResponseWriter, r *http.Request, log *slog.Logger, maxSize int64, req *MaskRequest) bool {
+	if err := r.ParseMultipartForm(maskMemory); err != nil {
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			resp.RenderError(w, r, maskTooLarge(maxSize))
+			return false
+		}
+		log.Error("Failed to parse multipart form", "error", err)
+		resp.RenderError(w, r, resp.BadRequest("Failed to parse multipart form"))
+		return false
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("mask")
+	if err != nil {
+		resp.RenderError(w, r, resp.BadRequest("Missing mask part"))
+		return false
+	}
+	defer file.Close()
+
+	if header.Size > maxSize {
+		resp.RenderError(w, r, maskTooLarge(maxSize))
+		return false
+	}
+
+	req.LabelID = r.FormValue("label_id")
+	req.Comment = r.FormValue("comment")
+
+	// check the size before decoding so a small file cannot expand into a huge bitmap
+	config, err := png.DecodeConfig(file)
+	if err != nil {
+		resp.RenderError(w, r, resp.Error(resp.CodeUnsupportedMedia, "Mask must be a PNG image"))
+		return false
+	}
+	if rle.TooManyPixels(config.Width, config.Height) {
+		resp.RenderError(w, r, resp.Invalid(maskSizeError(fmt.Sprintf("Mask exceeds %d pixels", rle.MaxPixels))))
+		return false
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		log.Error("Failed to rewind mask", "error", err)
+		resp.RenderError(w, r, resp.BadRequest("Failed to read mask"))
+		return false
+	}
+
+	img, err := png.Decode(file)
+	if err != nil {
+		log.Info("Failed to decode mask", "error", err)
+		resp.RenderError(w, r, resp.BadRequest("Mask is not a valid PNG image"))
+		return false
+	}
+	req.RLE = rle.FromImage(img)
+	return true
+}
+
+// checkMask verifies that the mask is present, covers the image pixel for pixel when
+// its dimensions are known, and has at least one foreground pixel.
+func checkMask(mask *rle.RLE, image *repository.Image) *resp.FieldError {
+	if mask == nil {
+		return &resp.FieldError{Field: "rle", Code: "required", Message: "Field 'rle' is required"}
+	}
+	if image.Metadata != nil && image.Metadata.Width > 0 && image.Metadata.Height > 0 {
+		if mask.Width != image.Metadata.Width || mask.Height != image.Metadata.Height {
+			details := maskSizeError(fmt.Sprintf("Mask is %dx%d px but the image is %dx%d px",
+				mask.Width, mask.Height, image.Metadata.Width, image.Metadata.Height))
+			return &details
+		}
+	} else if rle.TooManyPixels(mask.Width, mask.Height) {
+		details := maskSizeError(fmt.Sprintf("Mask exceeds %d pixels", rle.MaxPixels))
+		return &details
+	}
+	if mask.Area() == 0 {
+		return &resp.FieldError{Field: "rle", Code: "empty_mask", Message: "Mask has no foreground pixels"}
+	}
+	return nil
+}
+
+func maskTooLarge(maxSize int64) resp.Response {
+	return resp.Error(resp.CodeTooLarge, "Mask exceeds "+strconv.FormatInt(maxSize, 10)+" bytes")
+}
+
+func maskSizeError(msg string) resp.FieldError {
+	return resp.FieldError{Field: "rle", Code: "mask_size", Message: msg}
+}
@@ -0,0 +1,85 @@
+package annotation
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type ReviewAnnotationRequest struct {
+	Status string `json:"status" validate:"required,oneof=approved rejected"`
+}
+
+type ReviewAnnotationResponse struct {
+	Response   resp.Response          `json:"response"`
+	Annotation *repository.Annotation `json:"annotation"`
+}
+
+// ReviewAnnotationHandler approves or rejects an annotation on an image visible to the reviewer.
+func ReviewAnnotationHandler(annotations repository.Annotations, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.annotation.ReviewAnnotationHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "annotationID")
+		reviewer := mwAuth.UserFromContext(r.Context())
+
+		var req ReviewAnnotationRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		annotation, _, err := policy.ViewAnnotation(r.Context(), reviewer, id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("annotation_id", id)), err, "Annotation")
+			return
+		}
+
+		annotation.Status = req.Status
+		annotation.ReviewedBy = reviewer.ID
+
+		err = annotations.Review(r.Context(), annotation)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Annotation not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to review annotation", "error", err, slog.String("annotation_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to review annotation"))
+			return
+		}
+
+		log.Info(
+			"Annotation reviewed",
+			slog.String("annotation_id", id),
+			slog.String("status", annotation.Status),
+		)
+
+		render.JSON(w, r, ReviewAnnotationResponse{
+			Response:   resp.OK(),
+			Annotation: annotation,
+		})
+	}
+}
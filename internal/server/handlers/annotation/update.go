@@ -0,0 +1,160 @@
+package annotation
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// UpdateAnnotationRequest is a partial update: only the fields present in the body are changed.
+// An empty label_id removes the label. A geometry replaces the shape; for shapes other
+// than boxes x, y, width and height follow from its points and are ignored. Attributes
+// replace the current ones as a whole; an empty object removes them.
+type UpdateAnnotationRequest struct {
+	LabelID    *string            `json:"label_id" validate:"omitnil,omitempty,numeric"`
+	X          *float64           `json:"x" validate:"omitnil,gte=0"`
+	Y          *float64           `json:"y" validate:"omitnil,gte=0"`
+	Width      *float64           `json:"width" validate:"omitnil,gt=0"`
+	Height     *float64           `json:"height" validate:"omitnil,gt=0"`
+	Geometry   *geometry.Geometry `json:"geometry"`
+	Attributes map[string]any     `json:"attributes" validate:"omitnil,max=50"`
+	Comment    *string            `json:"comment" validate:"omitnil,max=2000"`
+}
+
+type UpdateAnnotationResponse struct {
+	Response   resp.Response          `json:"response"`
+	Annotation *repository.Annotation `json:"annotation"`
+}
+
+// UpdateAnnotationHandler applies a partial update and sends the annotation back to review.
+// A new label must be an active label of the image's project. With ?coords=normalized the
+// coordinates are read and returned as fractions of the image size.
+func UpdateAnnotationHandler(annotations repository.Annotations, labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.annotation.UpdateAnnotationHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "annotationID")
+
+		var req UpdateAnnotationRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+		log.Info("Request body decoded", slog.Any("request", req))
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		annotation, image, err := policy.EditAnnotation(r.Context(), mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("annotation_id", id)), err, "Annotation")
+			return
+		}
+
+		// the rectangle of a mask follows from its pixels, which are replaced with UpdateMaskHandler
+		reshaped := req.Geometry != nil || req.X != nil || req.Y != nil || req.Width != nil || req.Height != nil
+		if (annotation.Geometry.Type == geometry.Mask && reshaped) || (req.Geometry != nil && req.Geometry.Type == geometry.Mask) {
+			resp.RenderError(w, r, resp.Invalid(maskGeometryError))
+			return
+		}
+
+		normalized, ok := normalizedCoords(w, r, image)
+		if !ok {
+			return
+		}
+		if normalized {
+			normalize(annotation, image)
+		}
+
+		currentLabel := annotation.LabelID
+		if req.LabelID != nil {
+			annotation.LabelID = *req.LabelID
+		}
+		if req.X != nil {
+			annotation.X = *req.X
+		}
+		if req.Y != nil {
+			annotation.Y = *req.Y
+		}
+		if req.Width != nil {
+			annotation.Width = *req.Width
+		}
+		if req.Height != nil {
+			annotation.Height = *req.Height
+		}
+		if req.Comment != nil {
+			annotation.Comment = *req.Comment
+		}
+		if req.Geometry != nil {
+			annotation.Geometry = req.Geometry
+		}
+		if req.Attributes != nil {
+			annotation.Attributes = req.Attributes
+		}
+
+		if details := applyGeometry(annotation); details != nil {
+			resp.RenderError(w, r, resp.Invalid(details...))
+			return
+		}
+		if normalized {
+			denormalize(annotation, image)
+		}
+		if details := checkBounds(annotation, image); details != nil {
+			resp.RenderError(w, r, resp.Invalid(details...))
+			return
+		}
+
+		invalid, err := checkLabel(r.Context(), labels, annotation.LabelID, currentLabel, image)
+		if err != nil {
+			log.Error("Failed to check label", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to update annotation"))
+			return
+		}
+		if invalid != nil {
+			resp.RenderError(w, r, resp.Invalid(*invalid))
+			return
+		}
+
+		err = annotations.Update(r.Context(), annotation)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Annotation not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to update annotation", "error", err, slog.String("annotation_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to update annotation"))
+			return
+		}
+
+		log.Info("Annotation updated successfully", slog.String("annotation_id", id))
+
+		if normalized {
+			normalize(annotation, image)
+		}
+
+		render.JSON(w, r, UpdateAnnotationResponse{
+			Response:   resp.OK(),
+			Annotation: annotation,
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package annotation
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// UpdateMaskHandler replaces the pixels of a mask annotation, given like in CreateMaskHandler,
+// and sends it back to review. A label_id or comment in the request replaces the current
+// one when non-empty; use PATCH on the annotation to clear them.
+func UpdateMaskHandler(annotations repository.Annotations, labels repository.Labels, maxSize int64, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.annotation.UpdateMaskHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "annotationID")
+
+		annotation, image, err := policy.EditAnnotation(r.Context(), mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("annotation_id", id)), err, "Annotation")
+			return
+		}
+		if annotation.Geometry.Type != geometry.Mask {
+			resp.RenderError(w, r, resp.Invalid(resp.FieldError{
+				Field:   "geometry",
+				Code:    "invalid_geometry",
+				Message: "Annotation is a " + annotation.Geometry.Type + ", not a mask",
+			}))
+			return
+		}
+
+		normalized, ok := normalizedCoords(w, r, image)
+		if !ok {
+			return
+		}
+
+		req, ok := decodeMask(w, r, log, maxSize, image)
+		if !ok {
+			return
+		}
+
+		currentLabel := annotation.LabelID
+		if req.LabelID != "" {
+			annotation.LabelID = req.LabelID
+		}
+		if req.Comment != "" {
+			annotation.Comment = req.Comment
+		}
+
+		invalid, err := checkLabel(r.Context(), labels, annotation.LabelID, currentLabel, image)
+		if err != nil {
+			log.Error("Failed to check label", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to update annotation"))
+			return
+		}
+		if invalid != nil {
+			resp.RenderError(w, r, resp.Invalid(*invalid))
+			return
+		}
+
+		err = annotations.UpdateMask(r.Context(), annotation, req.RLE)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Annotation not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to update mask", "error", err, slog.String("annotation_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to update annotation"))
+			return
+		}
+
+		log.Info("Mask updated successfully", slog.String("annotation_id", id), slog.Int("area", annotation.Geometry.Area))
+
+		if normalized {
+			normalize(annotation, image)
+		}
+
+		render.JSON(w, r, UpdateAnnotationResponse{
+			Response:   resp.OK(),
+			Annotation: annotation,
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/lib/hash"
+	"github.com/Agero19/AnnotateX-api/internal/lib/token"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+func LoginHandler(users repository.Users, refreshTokens repository.RefreshTokens, tokens *token.Manager, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.LoginHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req LoginRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		user, err := users.GetByEmail(r.Context(), req.Email)
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Info("Login attempt for unknown email")
+			resp.RenderError(w, r, resp.Unauthorized("Invalid email or password"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get user", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to log in"))
+			return
+		}
+
+		ok, err := hash.CheckPassword(user.Password, req.Password)
+		if err != nil {
+			log.Error("Failed to check password", "error", err, slog.String("user_id", user.ID))
+			resp.RenderError(w, r, resp.Internal("Failed to log in"))
+			return
+		}
+		if !ok {
+			log.Info("Login attempt with wrong password", slog.String("user_id", user.ID))
+			resp.RenderError(w, r, resp.Unauthorized("Invalid email or password"))
+			return
+		}
+
+		response, err := issueTokens(r.Context(), tokens, refreshTokens, user.ID)
+		if err != nil {
+			log.Error("Failed to issue tokens", "error", err, slog.String("user_id", user.ID))
+			resp.RenderError(w, r, resp.Internal("Failed to log in"))
+			return
+		}
+
+		log.Info("User logged in", slog.String("user_id", user.ID))
+
+		render.JSON(w, r, response)
+	}
+}
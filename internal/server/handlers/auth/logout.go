@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/lib/token"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutHandler revokes the given refresh token. Unknown tokens are ignored so the call is idempotent.
+func LogoutHandler(refreshTokens repository.RefreshTokens, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.LogoutHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req LogoutRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		stored, err := refreshTokens.GetByHash(r.Context(), token.HashRefreshToken(req.RefreshToken))
+		if errors.Is(err, repository.ErrNotFound) {
+			render.JSON(w, r, resp.OK())
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get refresh token", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to log out"))
+			return
+		}
+
+		if _, err := refreshTokens.Revoke(r.Context(), stored.ID); err != nil {
+			log.Error("Failed to revoke refresh token", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to log out"))
+			return
+		}
+		log.Info("User logged out", slog.String("user_id", stored.UserID))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/lib/token"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// RefreshHandler exchanges a refresh token for a new token pair. The presented
+// refresh token is revoked; presenting an already revoked token is treated as
+// theft and revokes every session of its owner.
+func RefreshHandler(refreshTokens repository.RefreshTokens, tokens *token.Manager, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.RefreshHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req RefreshRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		stored, err := refreshTokens.GetByHash(r.Context(), token.HashRefreshToken(req.RefreshToken))
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.Unauthorized("Invalid refresh token"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get refresh token", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to refresh token"))
+			return
+		}
+		if stored.Expired() {
+			resp.RenderError(w, r, resp.Unauthorized("Invalid refresh token"))
+			return
+		}
+
+		revoked, err := refreshTokens.Revoke(r.Context(), stored.ID)
+		if err != nil {
+			log.Error("Failed to revoke refresh token", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to refresh token"))
+			return
+		}
+		if !revoked {
+			log.Warn("Revoked refresh token reused, revoking all sessions", slog.String("user_id", stored.UserID))
+			if err := refreshTokens.RevokeAllForUser(r.Context(), stored.UserID); err != nil {
+				log.Error("Failed to revoke user sessions", "error", err, slog.String("user_id", stored.UserID))
+			}
+			resp.RenderError(w, r, resp.Unauthorized("Invalid refresh token"))
+			return
+		}
+
+		response, err := issueTokens(r.Context(), tokens, refreshTokens, stored.UserID)
+		if err != nil {
+			log.Error("Failed to issue tokens", "error", err, slog.String("user_id", stored.UserID))
+			resp.RenderError(w, r, resp.Internal("Failed to refresh token"))
+			return
+		}
+
+		log.Info("Token refreshed", slog.String("user_id", stored.UserID))
+
+		render.JSON(w, r, response)
+	}
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/token"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// TokenResponse is returned by every endpoint that issues a new token pair.
+type TokenResponse struct {
+	Response     resp.Response `json:"response"`
+	AccessToken  string        `json:"access_token"`
+	RefreshToken string        `json:"refresh_token"`
+	TokenType    string        `json:"token_type"`
+	ExpiresIn    int           `json:"expires_in"`
+}
+
+// issueTokens signs a new access token and persists a new refresh token for the user.
+func issueTokens(ctx context.Context, tokens *token.Manager, refreshTokens repository.RefreshTokens, userID string) (*TokenResponse, error) {
+	const op = "handlers.auth.issueTokens"
+
+	access, err := tokens.NewAccessToken(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	raw, hashed, err := token.NewRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := refreshTokens.Create(ctx, &repository.RefreshToken{
+		UserID:    userID,
+		TokenHash: hashed,
+		ExpiresAt: time.Now().Add(tokens.RefreshTTL()),
+	}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &TokenResponse{
+		Response:     resp.OK(),
+		AccessToken:  access,
+		RefreshToken: raw,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(tokens.AccessTTL().Seconds()),
+	}, nil
+}
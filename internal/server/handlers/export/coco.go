@@ -0,0 +1,61 @@
+package export
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// ProjectCOCOHandler streams the project as a COCO detection dataset. Labels become
+// categories, and boxes, polygons, masks and keypoints become annotations. The JSON is
+// written as images are read, so an error midway truncates it and leaves it unparsable.
+func ProjectCOCOHandler(images repository.Images, annotations repository.Annotations, labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.export.ProjectCOCOHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		projectID := chi.URLParam(r, "projectID")
+
+		project, err := policy.ViewProject(r.Context(), mwAuth.UserFromContext(r.Context()), projectID)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", projectID)), err, "Project")
+			return
+		}
+
+		// archived labels are still on annotations
+		list, err := labels.GetByProjectID(r.Context(), projectID, true)
+		if err != nil {
+			log.Error("Failed to list labels", "error", err, slog.String("project_id", projectID))
+			resp.RenderError(w, r, resp.Internal("Failed to export project"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="project-`+projectID+`-coco.json"`)
+
+		imageCount, annotationCount, err := dataset.ExportCOCO(r.Context(), w, images, annotations, project, list)
+		if err != nil {
+			log.Error("Failed to write COCO export", "error", err, slog.String("project_id", projectID))
+			return
+		}
+
+		log.Info(
+			"Project exported",
+			slog.String("project_id", projectID),
+			slog.String("format", "coco"),
+			slog.Int("images", imageCount),
+			slog.Int("annotations", annotationCount),
+		)
+	}
+}
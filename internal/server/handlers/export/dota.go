@@ -0,0 +1,110 @@
+package export
+
+import (
+	"archive/zip"
+	"log/slog"
+	"net/http"
+
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// ImageDOTAHandler returns the image's boxes, rotated ones included, as a DOTA label file.
+func ImageDOTAHandler(annotations repository.Annotations, labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.export.ImageDOTAHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		imageID := chi.URLParam(r, "imageID")
+
+		image, err := policy.ViewImage(r.Context(), mwAuth.UserFromContext(r.Context()), imageID)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", imageID)), err, "Image")
+			return
+		}
+
+		names := map[string]string{}
+		if image.ProjectID != "" {
+			if names, err = dataset.LabelNames(r.Context(), labels, image.ProjectID); err != nil {
+				log.Error("Failed to list labels", "error", err, slog.String("project_id", image.ProjectID))
+				resp.RenderError(w, r, resp.Internal("Failed to export annotations"))
+				return
+			}
+		}
+
+		list, err := dataset.ImageAnnotations(r.Context(), annotations, image.ID)
+		if err != nil {
+			log.Error("Failed to list annotations", "error", err, slog.String("image_id", imageID))
+			resp.RenderError(w, r, resp.Internal("Failed to export annotations"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+image.ID+`.txt"`)
+		if err := dataset.WriteDOTA(w, list, names); err != nil {
+			log.Error("Failed to write DOTA export", "error", err, slog.String("image_id", imageID))
+		}
+	}
+}
+
+// ProjectDOTAHandler streams a zip with one DOTA label file per project image. The
+// archive is written as images are read, so an error midway truncates it; clients
+// detect that by the missing zip directory.
+func ProjectDOTAHandler(images repository.Images, annotations repository.Annotations, labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.export.ProjectDOTAHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		projectID := chi.URLParam(r, "projectID")
+
+		if _, err := policy.ViewProject(r.Context(), mwAuth.UserFromContext(r.Context()), projectID); err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", projectID)), err, "Project")
+			return
+		}
+
+		names, err := dataset.LabelNames(r.Context(), labels, projectID)
+		if err != nil {
+			log.Error("Failed to list labels", "error", err, slog.String("project_id", projectID))
+			resp.RenderError(w, r, resp.Internal("Failed to export project"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="project-`+projectID+`-dota.zip"`)
+
+		zw := zip.NewWriter(w)
+		count := 0
+		err = dataset.EachImage(r.Context(), images, annotations, projectID, false, func(image *repository.Image, list []*repository.Annotation, _ map[string]*rle.RLE) error {
+			f, err := zw.Create(dataset.DOTAFileName(image))
+			if err != nil {
+				return err
+			}
+			count++
+			return dataset.WriteDOTA(f, list, names)
+		})
+		if err != nil {
+			log.Error("Failed to write DOTA export", "error", err, slog.String("project_id", projectID))
+			return
+		}
+		if err := zw.Close(); err != nil {
+			log.Error("Failed to finish DOTA export", "error", err, slog.String("project_id", projectID))
+			return
+		}
+
+		log.Info("Project exported", slog.String("project_id", projectID), slog.String("format", "dota"), slog.Int("images", count))
+	}
+}
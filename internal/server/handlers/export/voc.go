@@ -0,0 +1,110 @@
+package export
+
+import (
+	"archive/zip"
+	"log/slog"
+	"net/http"
+
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// ImageVOCHandler returns the image's annotations as a Pascal VOC annotation file.
+func ImageVOCHandler(annotations repository.Annotations, labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.export.ImageVOCHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		imageID := chi.URLParam(r, "imageID")
+
+		image, err := policy.ViewImage(r.Context(), mwAuth.UserFromContext(r.Context()), imageID)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", imageID)), err, "Image")
+			return
+		}
+
+		names := map[string]string{}
+		if image.ProjectID != "" {
+			if names, err = dataset.LabelNames(r.Context(), labels, image.ProjectID); err != nil {
+				log.Error("Failed to list labels", "error", err, slog.String("project_id", image.ProjectID))
+				resp.RenderError(w, r, resp.Internal("Failed to export annotations"))
+				return
+			}
+		}
+
+		list, err := dataset.ImageAnnotations(r.Context(), annotations, image.ID)
+		if err != nil {
+			log.Error("Failed to list annotations", "error", err, slog.String("image_id", imageID))
+			resp.RenderError(w, r, resp.Internal("Failed to export annotations"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+image.ID+`.xml"`)
+		if err := dataset.WriteVOC(w, image, list, names); err != nil {
+			log.Error("Failed to write VOC export", "error", err, slog.String("image_id", imageID))
+		}
+	}
+}
+
+// ProjectVOCHandler streams a zip with one Pascal VOC annotation file per project image.
+// Like ProjectDOTAHandler it is written as images are read, so an error midway leaves the
+// archive without its zip directory.
+func ProjectVOCHandler(images repository.Images, annotations repository.Annotations, labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.export.ProjectVOCHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		projectID := chi.URLParam(r, "projectID")
+
+		if _, err := policy.ViewProject(r.Context(), mwAuth.UserFromContext(r.Context()), projectID); err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", projectID)), err, "Project")
+			return
+		}
+
+		names, err := dataset.LabelNames(r.Context(), labels, projectID)
+		if err != nil {
+			log.Error("Failed to list labels", "error", err, slog.String("project_id", projectID))
+			resp.RenderError(w, r, resp.Internal("Failed to export project"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="project-`+projectID+`-voc.zip"`)
+
+		zw := zip.NewWriter(w)
+		count := 0
+		err = dataset.EachImage(r.Context(), images, annotations, projectID, false, func(image *repository.Image, list []*repository.Annotation, _ map[string]*rle.RLE) error {
+			f, err := zw.Create(dataset.VOCFileName(image))
+			if err != nil {
+				return err
+			}
+			count++
+			return dataset.WriteVOC(f, image, list, names)
+		})
+		if err != nil {
+			log.Error("Failed to write VOC export", "error", err, slog.String("project_id", projectID))
+			return
+		}
+		if err := zw.Close(); err != nil {
+			log.Error("Failed to finish VOC export", "error", err, slog.String("project_id", projectID))
+			return
+		}
+
+		log.Info("Project exported", slog.String("project_id", projectID), slog.String("format", "voc"), slog.Int("images", count))
+	}
+}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Agero19/AnnotateX-api/internal/buildinfo"
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/go-chi/render"
+)
+
+// LivenessResponse represents the response of the liveness probe.
+type LivenessResponse struct {
+	Response resp.Response  `json:"response"`
+	Build    buildinfo.Info `json:"build"`
+}
+
+// LivenessHandler reports that the process is running and able to serve HTTP.
+// It deliberately checks no dependencies: a database outage must not get the pod restarted.
+func LivenessHandler(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, LivenessResponse{
+			Response: resp.OK(),
+			Build:    buildinfo.Get(),
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Agero19/AnnotateX-api/internal/buildinfo"
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check probes one dependency. A nil error means the dependency is up.
+type Check struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+// ComponentStatus is the outcome of a single Check.
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessResponse represents the response of the readiness probe.
+type ReadinessResponse struct {
+	Response   resp.Response              `json:"response"`
+	Components map[string]ComponentStatus `json:"components"`
+	Build      buildinfo.Info             `json:"build"`
+}
+
+// ReadinessHandler runs all checks concurrently, each bounded by timeout, and reports
+// 503 unless every component is up.
+func ReadinessHandler(checks []Check, timeout time.Duration, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.health.ReadinessHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		components := make(map[string]ComponentStatus, len(checks))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+
+		for _, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(r.Context(), timeout)
+				defer cancel()
+
+				start := time.Now()
+				err := check.Fn(ctx)
+				status := ComponentStatus{
+					Status:    StatusUp,
+					LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+				}
+				if err != nil {
+					status.Status = StatusDown
+					status.Error = err.Error()
+					log.Warn("Readiness check failed", slog.String("component", check.Name), "error", err)
+				}
+
+				mu.Lock()
+				components[check.Name] = status
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		response := ReadinessResponse{
+			Response:   resp.OK(),
+			Components: components,
+			Build:      buildinfo.Get(),
+		}
+		for _, c := range components {
+			if c.Status != StatusUp {
+				response.Response = resp.Error(resp.CodeUnavailable, "One or more components are unavailable")
+				render.Status(r, resp.HTTPStatus(resp.CodeUnavailable))
+				break
+			}
+		}
+
+		render.JSON(w, r, response)
+	}
+}
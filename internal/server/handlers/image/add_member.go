@@ -0,0 +1,80 @@
+package image
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type AddMemberRequest struct {
+	UserID string `json:"user_id" validate:"required,numeric"`
+}
+
+// AddMemberHandler shares a private image with another user. Only the image owner may share it.
+// Project images are shared through project membership instead.
+func AddMemberHandler(members repository.ImageMembers, users repository.Users, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.image.AddMemberHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "imageID")
+
+		var req AddMemberRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		image, err := policy.ManageImage(r.Context(), mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", id)), err, "Image")
+			return
+		}
+		if image.ProjectID != "" {
+			resp.RenderError(w, r, resp.BadRequest("Project images are shared by adding members to the project"))
+			return
+		}
+
+		member, err := users.GetByID(r.Context(), req.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("User not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get user", "error", err, slog.String("user_id", req.UserID))
+			resp.RenderError(w, r, resp.Internal("Failed to get user"))
+			return
+		}
+
+		if err := members.Add(r.Context(), id, member.ID); err != nil {
+			log.Error("Failed to add image member", "error", err, slog.String("image_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to add image member"))
+			return
+		}
+
+		log.Info("Image shared", slog.String("image_id", id), slog.String("user_id", member.ID))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
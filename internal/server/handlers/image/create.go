@@ -0,0 +1,97 @@
+package image
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateImageRequest struct {
+	ProjectID   string `json:"project_id" validate:"omitempty,numeric"`
+	URL         string `json:"url" validate:"required,url,max=255"`
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=2000"`
+	Visibility  *bool  `json:"visibility"`
+}
+
+type CreateImageResponse struct {
+	Response resp.Response     `json:"response"`
+	Image    *repository.Image `json:"image"`
+}
+
+// CreateImageHandler registers an image hosted elsewhere, optionally in a project the
+// user edits. Images default to public, or to private when added to a project. The file
+// is downloaded with client, up to maxSize bytes, to extract its metadata; images of more
+// than maxPixels are refused.
+func CreateImageHandler(repo repository.Images, client *http.Client, maxSize int64, maxPixels int, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.image.CreateImageHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req CreateImageRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+		log.Info("Request body decoded", slog.Any("request", req))
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		user := mwAuth.UserFromContext(r.Context())
+
+		if req.ProjectID != "" {
+			if _, err := policy.EditProject(r.Context(), user, req.ProjectID); err != nil {
+				access.RenderError(w, r, log.With(slog.String("project_id", req.ProjectID)), err, "Project")
+				return
+			}
+		}
+
+		image := &repository.Image{
+			UserID:      user.ID,
+			ProjectID:   req.ProjectID,
+			URL:         req.URL,
+			Title:       req.Title,
+			Description: req.Description,
+			Visibility:  req.ProjectID == "",
+		}
+		if req.Visibility != nil {
+			image.Visibility = *req.Visibility
+		}
+		meta, ok := fetchMetadata(w, r, client, image.URL, maxSize, maxPixels, log)
+		if !ok {
+			return
+		}
+		applyMetadata(image, meta)
+
+		if err := repo.Create(r.Context(), image); err != nil {
+			log.Error("Failed to create image", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to create image"))
+			return
+		}
+
+		log.Info("Image created successfully", slog.String("image_id", image.ID))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateImageResponse{
+			Response: resp.OK(),
+			Image:    image,
+		})
+	}
+}
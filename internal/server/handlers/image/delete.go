@@ -0,0 +1,53 @@
+package image
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// DeleteImageHandler deletes the image and, for uploaded images, the stored file and its thumbnails.
+func DeleteImageHandler(repo repository.Images, blob storage.Blob, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.image.DeleteImageHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "imageID")
+
+		image, err := policy.ManageImage(r.Context(), mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", id)), err, "Image")
+			return
+		}
+
+		err = repo.Delete(r.Context(), id)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Image not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to delete image", "error", err, slog.String("image_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to delete image"))
+			return
+		}
+
+		RemoveFiles(r.Context(), repo, blob, log, image)
+
+		log.Info("Image deleted successfully", slog.String("image_id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,105 @@
+package image
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/phash"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+const (
+	defaultDuplicateThreshold = 10
+	// beyond a quarter of the bits unrelated images start to match
+	maxDuplicateThreshold = 16
+)
+
+// DuplicateCluster is a group of near-identical images. MaxDistance is the
+// largest Hamming distance between two of its members' perceptual hashes.
+type DuplicateCluster struct {
+	ImageIDs    []string `json:"image_ids"`
+	MaxDistance int      `json:"max_distance"`
+}
+
+type ListDuplicatesResponse struct {
+	Response  resp.Response      `json:"response"`
+	Threshold int                `json:"threshold"`
+	Clusters  []DuplicateCluster `json:"clusters"`
+}
+
+// ListDuplicatesHandler groups the user's personal images, or the project's images when
+// mounted under a project, whose perceptual hashes differ by at most the "threshold"
+// query parameter, in bits. Images close to an image that is close to another end up
+// in the same cluster.
+func ListDuplicatesHandler(repo repository.Images, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.image.ListDuplicatesHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		threshold := defaultDuplicateThreshold
+		if v := r.URL.Query().Get("threshold"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > maxDuplicateThreshold {
+				resp.RenderError(w, r, resp.BadRequest("Query parameter 'threshold' must be an integer between 0 and "+strconv.Itoa(maxDuplicateThreshold)))
+				return
+			}
+			threshold = n
+		}
+
+		user := mwAuth.UserFromContext(r.Context())
+
+		projectID := chi.URLParam(r, "projectID")
+		if projectID != "" {
+			if _, err := policy.ViewProject(r.Context(), user, projectID); err != nil {
+				access.RenderError(w, r, log.With(slog.String("project_id", projectID)), err, "Project")
+				return
+			}
+		}
+
+		hashes, err := repo.GetPerceptualHashes(r.Context(), user.ID, projectID)
+		if err != nil {
+			log.Error("Failed to list perceptual hashes", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to list duplicates"))
+			return
+		}
+
+		items := make([]phash.Item, 0, len(hashes))
+		for _, h := range hashes {
+			parsed, err := phash.Parse(h.PerceptualHash)
+			if err != nil {
+				log.Error("Skipping image with invalid perceptual hash", "error", err, slog.String("image_id", h.ImageID))
+				continue
+			}
+			items = append(items, phash.Item{ID: h.ImageID, Hash: parsed})
+		}
+
+		clusters := []DuplicateCluster{}
+		for _, group := range phash.Cluster(items, threshold) {
+			cluster := DuplicateCluster{ImageIDs: make([]string, len(group))}
+			for i, item := range group {
+				cluster.ImageIDs[i] = item.ID
+				for _, other := range group[:i] {
+					cluster.MaxDistance = max(cluster.MaxDistance, phash.Distance(item.Hash, other.Hash))
+				}
+			}
+			clusters = append(clusters, cluster)
+		}
+
+		render.JSON(w, r, ListDuplicatesResponse{
+			Response:  resp.OK(),
+			Threshold: threshold,
+			Clusters:  clusters,
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+)
+
+// RemoveFiles deletes the stored original and thumbnails of a deleted image unless
+// another image still references them. Registered images have no stored files.
+func RemoveFiles(ctx context.Context, repo repository.Images, blob storage.Blob, log *slog.Logger, image *repository.Image) {
+	if image.ObjectKey == "" {
+		return
+	}
+	thumbnails := make([]string, 0, len(image.Thumbnails))
+	for _, t := range image.Thumbnails {
+		thumbnails = append(thumbnails, t.ObjectKey)
+	}
+	removeUnreferenced(ctx, repo, blob, log, image.ObjectKey, thumbnails...)
+}
+
+// removeUnreferenced deletes stored files once no image references the original
+// any more. Identical uploads share their files, so deleting one image must not
+// remove what another still uses. Failures only leave orphaned files behind and
+// are logged.
+//
+// An identical upload stores the file before recording its image, so it can pass
+// the reference check and have the file deleted under it; the upload then puts the
+// file back with restoreIfRemoved. The file is only lost if this delete lands after
+// that upload has already verified it, i.e. when the whole upload runs between the
+// reference check and the delete below.
+func removeUnreferenced(ctx context.Context, repo repository.Images, blob storage.Blob, log *slog.Logger, objectKey string, extra ...string) {
+	inUse, err := repo.HasObjectKey(ctx, objectKey)
+	if err != nil {
+		log.Error("Failed to check stored file references", "error", err, slog.String("key", objectKey))
+		return
+	}
+	if inUse {
+		return
+	}
+
+	for _, key := range append([]string{objectKey}, extra...) {
+		if err := blob.Delete(ctx, key); err != nil {
+			log.Error("Failed to delete stored file", "error", err, slog.String("key", key))
+		}
+	}
+}
+
+// restoreIfRemoved stores the file under key again if a concurrent removeUnreferenced
+// deleted it after it was put. Once the image referencing key is recorded, no later
+// delete removes it, so one check after creating the image is enough.
+func restoreIfRemoved(ctx context.Context, blob storage.Blob, key string, file io.ReadSeeker, size int64, contentType string) error {
+	rc, err := blob.Get(ctx, key)
+	if err == nil {
+		return rc.Close()
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return blob.Put(ctx, key, file, size, contentType)
+}
@@ -0,0 +1,43 @@
+package image
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type GetImageResponse struct {
+	Response resp.Response     `json:"response"`
+	Image    *repository.Image `json:"image"`
+}
+
+func GetImageHandler(policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.image.GetImageHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "imageID")
+
+		image, err := policy.ViewImage(r.Context(), mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", id)), err, "Image")
+			return
+		}
+
+		render.JSON(w, r, GetImageResponse{
+			Response: resp.OK(),
+			Image:    image,
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package image
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	"github.com/Agero19/AnnotateX-api/internal/server/listquery"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type ListImagesResponse struct {
+	Response   resp.Response       `json:"response"`
+	Images     []*repository.Image `json:"images"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// ListImagesHandler returns a page of the images visible to the authenticated user,
+// or of the project's images when mounted under a project. See listquery.Parse for the
+// paging parameters; images can be filtered by user_id, project_id, visibility,
+// mime_type, min_width, max_width, min_height and max_height.
+func ListImagesHandler(repo repository.Images, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.image.ListImagesHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		opts, err := listquery.Parse(r,
+			"user_id", "project_id", "visibility", "mime_type",
+			"min_width", "max_width", "min_height", "max_height",
+		)
+		if err != nil {
+			resp.RenderError(w, r, resp.BadRequest(err.Error()))
+			return
+		}
+
+		user := mwAuth.UserFromContext(r.Context())
+
+		if projectID := chi.URLParam(r, "projectID"); projectID != "" {
+			if _, err := policy.ViewProject(r.Context(), user, projectID); err != nil {
+				access.RenderError(w, r, log.With(slog.String("project_id", projectID)), err, "Project")
+				return
+			}
+			if opts.Filters == nil {
+				opts.Filters = map[string]string{}
+			}
+			opts.Filters["project_id"] = projectID
+		}
+
+		images, next, err := repo.GetAllVisibleTo(r.Context(), user.ID, opts)
+		if listquery.RenderError(w, r, err) {
+			return
+		}
+		if err != nil {
+			log.Error("Failed to list images", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to list images"))
+			return
+		}
+		if images == nil {
+			images = []*repository.Image{}
+		}
+
+		render.JSON(w, r, ListImagesResponse{
+			Response:   resp.OK(),
+			Images:     images,
+			NextCursor: next,
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package image
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/imagemeta"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// fetchMetadata downloads a registered image to read its metadata. Registration does not
+// depend on the URL being reachable, so failures are logged and yield nil. Only an image
+// with more than maxPixels is refused: it writes the error response and returns ok=false.
+func fetchMetadata(w http.ResponseWriter, r *http.Request, client *http.Client, url string, maxSize int64, maxPixels int, log *slog.Logger) (meta *imagemeta.Metadata, ok bool) {
+	meta, err := imagemeta.Fetch(r.Context(), client, url, maxSize, maxPixels)
+	if errors.Is(err, imagemeta.ErrTooManyPixels) {
+		resp.RenderError(w, r, resp.Invalid(tooManyPixels("url", maxPixels)))
+		return nil, false
+	}
+	if err != nil {
+		log.Warn("Failed to extract image metadata", "error", err, slog.String("url", url))
+		return nil, true
+	}
+	return meta, true
+}
+
+// tooManyPixels reports an image whose dimensions exceed maxPixels.
+func tooManyPixels(field string, maxPixels int) resp.FieldError {
+	return resp.FieldError{Field: field, Code: "too_many_pixels", Message: "Image exceeds " + strconv.Itoa(maxPixels) + " pixels"}
+}
+
+// applyMetadata records what was extracted from the file on the image; nil clears it.
+func applyMetadata(image *repository.Image, m *imagemeta.Metadata) {
+	if m == nil {
+		image.Metadata = nil
+		image.PerceptualHash = ""
+		return
+	}
+	image.Metadata = &repository.ImageMetadata{
+		Width:       m.Width,
+		Height:      m.Height,
+		MimeType:    m.MimeType,
+		SizeBytes:   m.Size,
+		ColorModel:  m.ColorModel,
+		CapturedAt:  m.CapturedAt,
+		CameraMake:  m.CameraMake,
+		CameraModel: m.CameraModel,
+		Orientation: m.Orientation,
+	}
+	image.PerceptualHash = m.PerceptualHash.String()
+}
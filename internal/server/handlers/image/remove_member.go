@@ -0,0 +1,44 @@
+package image
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// RemoveMemberHandler revokes a user's access to a shared image. Only the image owner may revoke it.
+func RemoveMemberHandler(members repository.ImageMembers, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.image.RemoveMemberHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "imageID")
+		userID := chi.URLParam(r, "userID")
+
+		if _, err := policy.ManageImage(r.Context(), mwAuth.UserFromContext(r.Context()), id); err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", id)), err, "Image")
+			return
+		}
+
+		if err := members.Remove(r.Context(), id, userID); err != nil {
+			log.Error("Failed to remove image member", "error", err, slog.String("image_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to remove image member"))
+			return
+		}
+
+		log.Info("Image unshared", slog.String("image_id", id), slog.String("user_id", userID))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
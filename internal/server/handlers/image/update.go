@@ -0,0 +1,125 @@
+package image
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// UpdateImageRequest is a partial update: only the fields present in the body are changed.
+// An empty project_id moves the image out of its project.
+type UpdateImageRequest struct {
+	ProjectID   *string `json:"project_id" validate:"omitempty,numeric"`
+	URL         *string `json:"url" validate:"omitempty,url,max=255"`
+	Title       *string `json:"title" validate:"omitempty,min=1,max=255"`
+	Description *string `json:"description" validate:"omitempty,max=2000"`
+	Visibility  *bool   `json:"visibility"`
+}
+
+type UpdateImageResponse struct {
+	Response resp.Response     `json:"response"`
+	Image    *repository.Image `json:"image"`
+}
+
+// UpdateImageHandler applies a partial update. Changing the URL of a registered
+// image re-extracts its metadata from the new location. Moving an image into a
+// project requires editing that project; its annotations then take the labels of the
+// same name in the new project and lose the labels it does not have.
+func UpdateImageHandler(repo repository.Images, client *http.Client, maxSize int64, maxPixels int, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.image.UpdateImageHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "imageID")
+
+		var req UpdateImageRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+		log.Info("Request body decoded", slog.Any("request", req))
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		user := mwAuth.UserFromContext(r.Context())
+
+		image, err := policy.ManageImage(r.Context(), user, id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("image_id", id)), err, "Image")
+			return
+		}
+
+		if req.ProjectID != nil && *req.ProjectID != image.ProjectID {
+			if *req.ProjectID != "" {
+				if _, err := policy.EditProject(r.Context(), user, *req.ProjectID); err != nil {
+					access.RenderError(w, r, log.With(slog.String("project_id", *req.ProjectID)), err, "Project")
+					return
+				}
+			}
+			image.ProjectID = *req.ProjectID
+		}
+
+		if req.URL != nil && *req.URL != image.URL {
+			if image.ObjectKey != "" {
+				resp.RenderError(w, r, resp.BadRequest("The URL of an uploaded image cannot be changed"))
+				return
+			}
+			image.URL = *req.URL
+			meta, ok := fetchMetadata(w, r, client, image.URL, maxSize, maxPixels, log)
+			if !ok {
+				return
+			}
+			applyMetadata(image, meta)
+		}
+		if req.Title != nil {
+			image.Title = *req.Title
+		}
+		if req.Description != nil {
+			image.Description = *req.Description
+		}
+		if req.Visibility != nil {
+			image.Visibility = *req.Visibility
+		}
+
+		err = repo.Update(r.Context(), image)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Image not found"))
+			return
+		}
+		if errors.Is(err, repository.ErrDuplicate) {
+			resp.RenderError(w, r, resp.Conflict("This file was already uploaded to the project"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to update image", "error", err, slog.String("image_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to update image"))
+			return
+		}
+
+		log.Info("Image updated successfully", slog.String("image_id", id))
+
+		render.JSON(w, r, UpdateImageResponse{
+			Response: resp.OK(),
+			Image:    image,
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/lib/imagemeta"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+	"github.com/Agero19/AnnotateX-api/internal/thumbnail"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// uploadMemory is how much of a multipart body is kept in memory before spilling to temporary files.
+const uploadMemory = 8 << 20
+
+// allowedTypes maps the sniffed content types accepted for upload to their file extension.
+var allowedTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// UploadImageRequest holds the non-file form fields of an upload.
+type UploadImageRequest struct {
+	ProjectID   string `json:"project_id" validate:"omitempty,numeric"`
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=2000"`
+	Visibility  bool   `json:"visibility"`
+}
+
+// UploadImageHandler accepts a multipart form with a "file" part and optional
+// "project_id", "title", "description" and "visibility" fields, stores the file
+// in blob storage and records the image. Without "visibility" the image is public,
+// or private when uploaded to a project. Thumbnails are generated in the background.
+// Images of more than maxPixels are refused before their pixels are decoded.
+// A file already uploaded to the same project, or among the user's personal images
+// without a project, is rejected with the ID of the existing image.
+func UploadImageHandler(repo repository.Images, blob storage.Blob, thumbnails *thumbnail.Worker, maxSize int64, maxPixels int, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.image.UploadImageHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		// leave room for the other form fields on top of the file itself
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+		if err := r.ParseMultipartForm(uploadMemory); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				resp.RenderError(w, r, resp.Error(resp.CodeTooLarge, "Upload exceeds "+strconv.FormatInt(maxSize, 10)+" bytes"))
+				return
+			}
+			log.Error("Failed to parse multipart form", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to parse multipart form"))
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			resp.RenderError(w, r, resp.BadRequest("Missing file part"))
+			return
+		}
+		defer file.Close()
+
+		if header.Size > maxSize {
+			resp.RenderError(w, r, resp.Error(resp.CodeTooLarge, "Upload exceeds "+strconv.FormatInt(maxSize, 10)+" bytes"))
+			return
+		}
+
+		req := UploadImageRequest{
+			ProjectID:   r.FormValue("project_id"),
+			Title:       r.FormValue("title"),
+			Description: r.FormValue("description"),
+			Visibility:  r.FormValue("project_id") == "",
+		}
+		if req.Title == "" {
+			req.Title = header.Filename
+		}
+		if v := r.FormValue("visibility"); v != "" {
+			req.Visibility, err = strconv.ParseBool(v)
+			if err != nil {
+				resp.RenderError(w, r, resp.BadRequest("Field 'visibility' must be a boolean"))
+				return
+			}
+		}
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		user := mwAuth.UserFromContext(r.Context())
+
+		if req.ProjectID != "" {
+			if _, err := policy.EditProject(r.Context(), user, req.ProjectID); err != nil {
+				access.RenderError(w, r, log.With(slog.String("project_id", req.ProjectID)), err, "Project")
+				return
+			}
+		}
+
+		contentType, err := sniffContentType(file)
+		if err != nil {
+			log.Error("Failed to read upload", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to read upload"))
+			return
+		}
+		ext, ok := allowedTypes[contentType]
+		if !ok {
+			resp.RenderError(w, r, resp.Error(resp.CodeUnsupportedMedia, "Unsupported image type "+contentType))
+			return
+		}
+
+		meta, err := imagemeta.Extract(file, header.Size, maxPixels)
+		if errors.Is(err, imagemeta.ErrUnsupported) {
+			resp.RenderError(w, r, resp.Error(resp.CodeUnsupportedMedia, "Unsupported image type "+contentType))
+			return
+		}
+		if errors.Is(err, imagemeta.ErrTooManyPixels) {
+			resp.RenderError(w, r, resp.Invalid(tooManyPixels("file", maxPixels)))
+			return
+		}
+		if err != nil {
+			log.Info("Failed to decode upload", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("File is not a valid "+contentType+" image"))
+			return
+		}
+
+		hash, err := contentHash(file)
+		if err != nil {
+			log.Error("Failed to hash upload", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to read upload"))
+			return
+		}
+
+		existing, err := repo.GetByContentHash(r.Context(), user.ID, req.ProjectID, hash)
+		if err == nil {
+			renderDuplicate(w, r, log, existing.ID)
+			return
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Error("Failed to look up duplicate", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to store image"))
+			return
+		}
+
+		// identical files share one object, so storing it again only rewrites the same bytes
+		key := "images/" + hash[:2] + "/" + hash + ext
+
+		if err := blob.Put(r.Context(), key, file, header.Size, contentType); err != nil {
+			log.Error("Failed to store image", "error", err, slog.String("key", key))
+			resp.RenderError(w, r, resp.Internal("Failed to store image"))
+			return
+		}
+
+		image := &repository.Image{
+			UserID:      user.ID,
+			ProjectID:   req.ProjectID,
+			URL:         blob.URL(key),
+			Title:       req.Title,
+			Description: req.Description,
+			Visibility:  req.Visibility,
+			ObjectKey:   key,
+			ContentHash: hash,
+			// picked up by the thumbnail worker
+			ThumbnailStatus: repository.ThumbnailPending,
+		}
+		applyMetadata(image, meta)
+
+		err = repo.Create(r.Context(), image)
+		if errors.Is(err, repository.ErrDuplicate) {
+			// a concurrent upload of the same file won the race
+			if existing, err := repo.GetByContentHash(r.Context(), user.ID, req.ProjectID, hash); err == nil {
+				renderDuplicate(w, r, log, existing.ID)
+				return
+			}
+		}
+		if err != nil {
+			log.Error("Failed to create image", "error", err)
+			removeUnreferenced(r.Context(), repo, blob, log, key)
+			resp.RenderError(w, r, resp.Internal("Failed to create image"))
+			return
+		}
+
+		// deleting an identical image may have removed the shared file since it was put
+		if err := restoreIfRemoved(r.Context(), blob, key, file, header.Size, contentType); err != nil {
+			log.Error("Failed to verify stored image", "error", err, slog.String("key", key))
+			if err := repo.Delete(r.Context(), image.ID); err != nil {
+				log.Error("Failed to delete image without a stored file", "error", err, slog.String("image_id", image.ID))
+			}
+			resp.RenderError(w, r, resp.Internal("Failed to store image"))
+			return
+		}
+
+		thumbnails.Notify()
+
+		log.Info(
+			"Image uploaded successfully",
+			slog.String("image_id", image.ID),
+			slog.String("key", key),
+			slog.Int64("size", header.Size),
+		)
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateImageResponse{
+			Response: resp.OK(),
+			Image:    image,
+		})
+	}
+}
+
+// sniffContentType detects the content type from the first bytes of the file and rewinds it.
+func sniffContentType(file multipart.File) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// contentHash returns the hex SHA-256 of the file and rewinds it.
+func contentHash(file multipart.File) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// DuplicateImageResponse is returned with 409 Conflict when the file was already uploaded.
+type DuplicateImageResponse struct {
+	Response resp.Response `json:"response"`
+	ImageID  string        `json:"image_id"`
+}
+
+func renderDuplicate(w http.ResponseWriter, r *http.Request, log *slog.Logger, imageID string) {
+	log.Info("Duplicate upload rejected", slog.String("image_id", imageID))
+
+	render.Status(r, http.StatusConflict)
+	render.JSON(w, r, DuplicateImageResponse{
+		Response: resp.Conflict("This file was already uploaded"),
+		ImageID:  imageID,
+	})
+}
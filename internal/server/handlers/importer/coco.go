@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// ProjectCOCOImportHandler imports a COCO dataset, sent as the JSON body of at most maxSize
+// bytes, into a project the user can edit. Images declared larger than maxPixels are
+// conflicts. Labels, images and annotations are written in a single transaction. With
+// ?dry_run=true nothing is written and the report tells what would be; otherwise any
+// conflict in the file rejects the whole import with 409.
+func ProjectCOCOImportHandler(images repository.Images, annotations repository.Annotations, labels repository.Labels, imports repository.Imports, maxSize int64, maxPixels int, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.importer.ProjectCOCOImportHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		projectID := chi.URLParam(r, "projectID")
+
+		dryRun, ok := parseDryRun(w, r)
+		if !ok {
+			return
+		}
+
+		user := mwAuth.UserFromContext(r.Context())
+		project, err := policy.EditProject(r.Context(), user, projectID)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", projectID)), err, "Project")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+		var coco dataset.COCO
+		if err := json.NewDecoder(r.Body).Decode(&coco); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				resp.RenderError(w, r, resp.Error(resp.CodeTooLarge, "COCO file exceeds "+strconv.FormatInt(maxSize, 10)+" bytes"))
+				return
+			}
+			log.Info("Failed to decode COCO file", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Invalid COCO file: "+err.Error()))
+			return
+		}
+
+		plan, err := dataset.PlanCOCOImport(r.Context(), &coco, project, user.ID, maxPixels, images, annotations, labels)
+		if err != nil {
+			log.Error("Failed to plan COCO import", "error", err, slog.String("project_id", projectID))
+			resp.RenderError(w, r, resp.Internal("Failed to import COCO file"))
+			return
+		}
+
+		if dryRun {
+			render.JSON(w, r, ImportResponse{Response: resp.OK(), DryRun: true, Report: plan.Report})
+			return
+		}
+		if plan.Report.ConflictCount > 0 {
+			renderConflicts(w, r, "COCO file", plan.Report)
+			return
+		}
+
+		err = imports.Import(r.Context(), &plan.Batch)
+		if errors.Is(err, repository.ErrDuplicate) {
+			// a label of the same name was created while the import was planned
+			resp.RenderError(w, r, resp.Conflict("Project labels changed during the import, please retry"))
+			return
+		}
+		if errors.Is(err, repository.ErrForeignKey) {
+			resp.RenderError(w, r, resp.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to import COCO file", "error", err, slog.String("project_id", projectID))
+			resp.RenderError(w, r, resp.Internal("Failed to import COCO file"))
+			return
+		}
+
+		log.Info(
+			"COCO file imported",
+			slog.String("project_id", projectID),
+			slog.Int("labels", plan.Report.LabelsCreated),
+			slog.Int("images", plan.Report.ImagesCreated),
+			slog.Int("annotations", plan.Report.Annotations),
+		)
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, ImportResponse{Response: resp.OK(), Report: plan.Report})
+	}
+}
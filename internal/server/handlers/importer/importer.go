@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+)
+
+type ImportResponse struct {
+	Response resp.Response        `json:"response"`
+	DryRun   bool                 `json:"dry_run"`
+	Report   dataset.ImportReport `json:"report"`
+}
+
+// parseDryRun reads the "dry_run" query parameter. It writes a 400 response and returns
+// ok=false if the value is not a boolean.
+func parseDryRun(w http.ResponseWriter, r *http.Request) (dryRun, ok bool) {
+	v := r.URL.Query().Get("dry_run")
+	if v == "" {
+		return false, true
+	}
+	dryRun, err := strconv.ParseBool(v)
+	if err != nil {
+		resp.RenderError(w, r, resp.BadRequest("Query parameter 'dry_run' must be a boolean"))
+		return false, false
+	}
+	return dryRun, true
+}
+
+// renderConflicts rejects the import of the file, e.g. "COCO file", with the reported
+// conflicts as details.
+func renderConflicts(w http.ResponseWriter, r *http.Request, file string, report dataset.ImportReport) {
+	res := resp.Conflict(fmt.Sprintf("%s has %d conflicts; use dry_run=true to review them", file, report.ConflictCount))
+	for _, c := range report.Conflicts {
+		res.Error.Details = append(res.Error.Details, resp.FieldError{Field: c.Item, Code: c.Code, Message: c.Message})
+	}
+	resp.RenderError(w, r, res)
+}
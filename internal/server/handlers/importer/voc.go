@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// ProjectVOCImportHandler imports Pascal VOC annotation files, sent as a zip body of at most
+// maxSize bytes, into a project the user can edit. Objects of images already in the project
+// become box annotations; images are never created since VOC files have no URL. Dry runs and
+// conflicts behave as in ProjectCOCOImportHandler.
+func ProjectVOCImportHandler(images repository.Images, labels repository.Labels, imports repository.Imports, maxSize int64, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.importer.ProjectVOCImportHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		projectID := chi.URLParam(r, "projectID")
+
+		dryRun, ok := parseDryRun(w, r)
+		if !ok {
+			return
+		}
+
+		user := mwAuth.UserFromContext(r.Context())
+		project, err := policy.EditProject(r.Context(), user, projectID)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", projectID)), err, "Project")
+			return
+		}
+
+		// the zip directory is at the end, so the archive is read whole before it is opened
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				resp.RenderError(w, r, resp.Error(resp.CodeTooLarge, "VOC archive exceeds "+strconv.FormatInt(maxSize, 10)+" bytes"))
+				return
+			}
+			log.Error("Failed to read VOC archive", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to read VOC archive"))
+			return
+		}
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			log.Info("Failed to open VOC archive", "error", err)
+			resp.RenderError(w, r, resp.Error(resp.CodeUnsupportedMedia, "VOC annotations must be sent as a zip archive"))
+			return
+		}
+
+		plan, err := dataset.PlanVOCImport(r.Context(), archive, project, user.ID, images, labels)
+		if err != nil {
+			log.Error("Failed to plan VOC import", "error", err, slog.String("project_id", projectID))
+			resp.RenderError(w, r, resp.Internal("Failed to import VOC archive"))
+			return
+		}
+
+		if dryRun {
+			render.JSON(w, r, ImportResponse{Response: resp.OK(), DryRun: true, Report: plan.Report})
+			return
+		}
+		if plan.Report.ConflictCount > 0 {
+			renderConflicts(w, r, "VOC archive", plan.Report)
+			return
+		}
+
+		err = imports.Import(r.Context(), &plan.Batch)
+		if errors.Is(err, repository.ErrDuplicate) {
+			// a label of the same name was created while the import was planned
+			resp.RenderError(w, r, resp.Conflict("Project labels changed during the import, please retry"))
+			return
+		}
+		if errors.Is(err, repository.ErrForeignKey) {
+			// the project or one of its images was deleted while the import was planned
+			resp.RenderError(w, r, resp.NotFound("Project or image not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to import VOC archive", "error", err, slog.String("project_id", projectID))
+			resp.RenderError(w, r, resp.Internal("Failed to import VOC archive"))
+			return
+		}
+
+		log.Info(
+			"VOC archive imported",
+			slog.String("project_id", projectID),
+			slog.Int("labels", plan.Report.LabelsCreated),
+			slog.Int("annotations", plan.Report.Annotations),
+		)
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, ImportResponse{Response: resp.OK(), Report: plan.Report})
+	}
+}
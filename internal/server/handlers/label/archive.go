@@ -0,0 +1,64 @@
+package label
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type ArchiveLabelResponse struct {
+	Response resp.Response     `json:"response"`
+	Label    *repository.Label `json:"label"`
+}
+
+// ArchiveLabelHandler archives the label when archived is true and restores it otherwise.
+// Archived labels stay on existing annotations but cannot be assigned to new ones, and
+// their name can be reused.
+func ArchiveLabelHandler(labels repository.Labels, policy *access.Policy, archived bool, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.label.ArchiveLabelHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "labelID")
+
+		label, err := manageLabel(r.Context(), labels, policy, mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("label_id", id)), err, "Label")
+			return
+		}
+
+		err = labels.SetArchived(r.Context(), label, archived)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Label not found"))
+			return
+		}
+		if errors.Is(err, repository.ErrDuplicate) {
+			resp.RenderError(w, r, resp.Conflict("A label named "+label.Name+" already exists in this project"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to archive label", "error", err, slog.String("label_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to archive label"))
+			return
+		}
+
+		log.Info("Label archive state changed", slog.String("label_id", id), slog.Bool("archived", archived))
+
+		render.JSON(w, r, ArchiveLabelResponse{
+			Response: resp.OK(),
+			Label:    label,
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package label
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// defaultColor is used for labels created without a color.
+const defaultColor = "#808080"
+
+type CreateLabelRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Color       string `json:"color" validate:"omitempty,len=7,hexcolor"`
+	Description string `json:"description" validate:"max=2000"`
+	ParentID    string `json:"parent_id" validate:"omitempty,numeric"`
+}
+
+type CreateLabelResponse struct {
+	Response resp.Response     `json:"response"`
+	Label    *repository.Label `json:"label"`
+}
+
+// CreateLabelHandler adds a label to the project's taxonomy. Only project owners may change the taxonomy.
+func CreateLabelHandler(labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.label.CreateLabelHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		projectID := chi.URLParam(r, "projectID")
+
+		var req CreateLabelRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+		log.Info("Request body decoded", slog.Any("request", req))
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		if _, err := policy.ManageProject(r.Context(), mwAuth.UserFromContext(r.Context()), projectID); err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", projectID)), err, "Project")
+			return
+		}
+
+		if req.ParentID != "" {
+			err := checkParent(r.Context(), labels, projectID, req.ParentID)
+			if errors.Is(err, errInvalidParent) {
+				resp.RenderError(w, r, resp.Invalid(resp.FieldError{Field: "parent_id", Code: "invalid_parent", Message: "Parent must be an active label of the same project"}))
+				return
+			}
+			if err != nil {
+				log.Error("Failed to check parent label", "error", err)
+				resp.RenderError(w, r, resp.Internal("Failed to create label"))
+				return
+			}
+		}
+
+		label := &repository.Label{
+			ProjectID:   projectID,
+			ParentID:    req.ParentID,
+			Name:        req.Name,
+			Color:       req.Color,
+			Description: req.Description,
+		}
+		if label.Color == "" {
+			label.Color = defaultColor
+		}
+
+		err := labels.Create(r.Context(), label)
+		if errors.Is(err, repository.ErrDuplicate) {
+			resp.RenderError(w, r, resp.Conflict("A label named "+req.Name+" already exists in this project"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to create label", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to create label"))
+			return
+		}
+
+		log.Info("Label created successfully", slog.String("label_id", label.ID), slog.String("project_id", projectID))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateLabelResponse{
+			Response: resp.OK(),
+			Label:    label,
+		})
+	}
+}
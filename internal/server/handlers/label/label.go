@@ -0,0 +1,41 @@
+package label
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+)
+
+// manageLabel loads the label if the user owns its project. A label of a project the
+// user cannot see is reported as not found.
+func manageLabel(ctx context.Context, labels repository.Labels, policy *access.Policy, user *repository.User, id string) (*repository.Label, error) {
+	label, err := labels.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := policy.ManageProject(ctx, user, label.ProjectID); err != nil {
+		return nil, err
+	}
+	return label, nil
+}
+
+// errInvalidParent is returned by checkParent when the parent is not an active label of the project.
+var errInvalidParent = errors.New("parent must be an active label of the same project")
+
+// checkParent verifies that parentID names an active label of the project.
+func checkParent(ctx context.Context, labels repository.Labels, projectID, parentID string) error {
+	parent, err := labels.GetByID(ctx, parentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return errInvalidParent
+	}
+	if err != nil {
+		return fmt.Errorf("get parent label: %w", err)
+	}
+	if parent.ProjectID != projectID || parent.ArchivedAt != "" {
+		return errInvalidParent
+	}
+	return nil
+}
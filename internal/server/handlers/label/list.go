@@ -0,0 +1,64 @@
+package label
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type ListLabelsResponse struct {
+	Response resp.Response       `json:"response"`
+	Labels   []*repository.Label `json:"labels"`
+}
+
+// ListLabelsHandler returns the project's taxonomy ordered by name. Archived labels are
+// included when the "archived" query parameter is true. Any project member may list them.
+func ListLabelsHandler(labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.label.ListLabelsHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		projectID := chi.URLParam(r, "projectID")
+
+		var archived bool
+		if v := r.URL.Query().Get("archived"); v != "" {
+			var err error
+			if archived, err = strconv.ParseBool(v); err != nil {
+				resp.RenderError(w, r, resp.BadRequest("Query parameter 'archived' must be a boolean"))
+				return
+			}
+		}
+
+		if _, err := policy.ViewProject(r.Context(), mwAuth.UserFromContext(r.Context()), projectID); err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", projectID)), err, "Project")
+			return
+		}
+
+		list, err := labels.GetByProjectID(r.Context(), projectID, archived)
+		if err != nil {
+			log.Error("Failed to list labels", "error", err, slog.String("project_id", projectID))
+			resp.RenderError(w, r, resp.Internal("Failed to list labels"))
+			return
+		}
+		if list == nil {
+			list = []*repository.Label{}
+		}
+
+		render.JSON(w, r, ListLabelsResponse{
+			Response: resp.OK(),
+			Labels:   list,
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package label
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type MergeLabelRequest struct {
+	IntoID string `json:"into_id" validate:"required,numeric"`
+}
+
+type MergeLabelResponse struct {
+	Response resp.Response     `json:"response"`
+	Label    *repository.Label `json:"label"`
+	// Relabeled is the number of annotations moved onto the target label
+	Relabeled int64 `json:"relabeled"`
+}
+
+// MergeLabelHandler folds the label into another active label of the same project:
+// its annotations and child labels move to the target and the label is deleted.
+func MergeLabelHandler(labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.label.MergeLabelHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "labelID")
+
+		var req MergeLabelRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		source, err := manageLabel(r.Context(), labels, policy, mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("label_id", id)), err, "Label")
+			return
+		}
+		if req.IntoID == source.ID {
+			resp.RenderError(w, r, resp.Invalid(resp.FieldError{Field: "into_id", Code: "invalid_target", Message: "A label cannot be merged into itself"}))
+			return
+		}
+
+		target, err := labels.GetByID(r.Context(), req.IntoID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			log.Error("Failed to get target label", "error", err, slog.String("label_id", req.IntoID))
+			resp.RenderError(w, r, resp.Internal("Failed to merge label"))
+			return
+		}
+		if err != nil || target.ProjectID != source.ProjectID || target.ArchivedAt != "" {
+			resp.RenderError(w, r, resp.Invalid(resp.FieldError{Field: "into_id", Code: "invalid_target", Message: "Target must be an active label of the same project"}))
+			return
+		}
+
+		relabeled, err := labels.Merge(r.Context(), source.ID, target.ID)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			resp.RenderError(w, r, resp.NotFound("Label not found"))
+			return
+		case errors.Is(err, repository.ErrLabelCycle):
+			resp.RenderError(w, r, resp.Invalid(resp.FieldError{Field: "into_id", Code: "cycle", Message: "A label cannot be merged into one of its descendants"}))
+			return
+		case err != nil:
+			log.Error("Failed to merge label", "error", err, slog.String("label_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to merge label"))
+			return
+		}
+
+		log.Info(
+			"Label merged",
+			slog.String("label_id", source.ID),
+			slog.String("into_id", target.ID),
+			slog.Int64("relabeled", relabeled),
+		)
+
+		render.JSON(w, r, MergeLabelResponse{
+			Response:  resp.OK(),
+			Label:     target,
+			Relabeled: relabeled,
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package label
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// UpdateLabelRequest is a partial update: only the fields present in the body are changed.
+// An empty parent_id moves the label to the top level.
+type UpdateLabelRequest struct {
+	Name        *string `json:"name" validate:"omitnil,min=1,max=255"`
+	Color       *string `json:"color" validate:"omitnil,len=7,hexcolor"`
+	Description *string `json:"description" validate:"omitnil,max=2000"`
+	ParentID    *string `json:"parent_id" validate:"omitempty,numeric"`
+}
+
+type UpdateLabelResponse struct {
+	Response resp.Response     `json:"response"`
+	Label    *repository.Label `json:"label"`
+}
+
+// UpdateLabelHandler renames, recolors, redescribes or reparents a label. Annotations keep
+// pointing at the label, so a rename applies to all of them.
+func UpdateLabelHandler(labels repository.Labels, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.label.UpdateLabelHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "labelID")
+
+		var req UpdateLabelRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+		log.Info("Request body decoded", slog.Any("request", req))
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		label, err := manageLabel(r.Context(), labels, policy, mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("label_id", id)), err, "Label")
+			return
+		}
+
+		if req.ParentID != nil && *req.ParentID != label.ParentID {
+			if *req.ParentID != "" {
+				err := checkParent(r.Context(), labels, label.ProjectID, *req.ParentID)
+				if errors.Is(err, errInvalidParent) {
+					resp.RenderError(w, r, resp.Invalid(resp.FieldError{Field: "parent_id", Code: "invalid_parent", Message: "Parent must be an active label of the same project"}))
+					return
+				}
+				if err != nil {
+					log.Error("Failed to check parent label", "error", err)
+					resp.RenderError(w, r, resp.Internal("Failed to update label"))
+					return
+				}
+			}
+			label.ParentID = *req.ParentID
+		}
+		if req.Name != nil {
+			label.Name = *req.Name
+		}
+		if req.Color != nil {
+			label.Color = *req.Color
+		}
+		if req.Description != nil {
+			label.Description = *req.Description
+		}
+
+		err = labels.Update(r.Context(), label)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			resp.RenderError(w, r, resp.NotFound("Label not found"))
+			return
+		case errors.Is(err, repository.ErrDuplicate):
+			resp.RenderError(w, r, resp.Conflict("A label named "+label.Name+" already exists in this project"))
+			return
+		case errors.Is(err, repository.ErrLabelCycle):
+			resp.RenderError(w, r, resp.Invalid(resp.FieldError{Field: "parent_id", Code: "cycle", Message: "A label cannot be nested under itself or its descendants"}))
+			return
+		case err != nil:
+			log.Error("Failed to update label", "error", err, slog.String("label_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to update label"))
+			return
+		}
+
+		log.Info("Label updated successfully", slog.String("label_id", id))
+
+		render.JSON(w, r, UpdateLabelResponse{
+			Response: resp.OK(),
+			Label:    label,
+		})
+	}
+}
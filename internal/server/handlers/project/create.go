@@ -0,0 +1,70 @@
+package project
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateProjectRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=2000"`
+}
+
+type CreateProjectResponse struct {
+	Response resp.Response       `json:"response"`
+	Project  *repository.Project `json:"project"`
+}
+
+// CreateProjectHandler creates a project owned by the authenticated user.
+func CreateProjectHandler(repo repository.Projects, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.project.CreateProjectHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req CreateProjectRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+		log.Info("Request body decoded", slog.Any("request", req))
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		project := &repository.Project{
+			Name:        req.Name,
+			Description: req.Description,
+			CreatedBy:   mwAuth.UserFromContext(r.Context()).ID,
+		}
+
+		if err := repo.Create(r.Context(), project); err != nil {
+			log.Error("Failed to create project", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to create project"))
+			return
+		}
+
+		log.Info("Project created successfully", slog.String("project_id", project.ID))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateProjectResponse{
+			Response: resp.OK(),
+			Project:  project,
+		})
+	}
+}
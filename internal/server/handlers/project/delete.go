@@ -0,0 +1,77 @@
+package project
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	"github.com/Agero19/AnnotateX-api/internal/server/handlers/image"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// DeleteProjectHandler deletes a project with its images and annotations, then the
+// stored files no other image uses. Only project owners may delete it.
+func DeleteProjectHandler(repo repository.Projects, images repository.Images, blob storage.Blob, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.project.DeleteProjectHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "projectID")
+
+		if _, err := policy.ManageProject(r.Context(), mwAuth.UserFromContext(r.Context()), id); err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", id)), err, "Project")
+			return
+		}
+
+		// the rows go with the project, so collect their files first
+		var uploaded []*repository.Image
+		opts := repository.ListOptions{Limit: repository.MaxLimit, Filters: map[string]string{"project_id": id}}
+		for {
+			page, next, err := images.GetAll(r.Context(), opts)
+			if err != nil {
+				log.Error("Failed to list project images", "error", err, slog.String("project_id", id))
+				resp.RenderError(w, r, resp.Internal("Failed to delete project"))
+				return
+			}
+			for _, img := range page {
+				if img.ObjectKey != "" {
+					uploaded = append(uploaded, img)
+				}
+			}
+			if next == "" {
+				break
+			}
+			opts.Cursor = next
+		}
+
+		err := repo.Delete(r.Context(), id)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to delete project", "error", err, slog.String("project_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to delete project"))
+			return
+		}
+
+		for _, img := range uploaded {
+			image.RemoveFiles(r.Context(), images, blob, log, img)
+		}
+
+		log.Info("Project deleted successfully", slog.String("project_id", id), slog.Int("images", len(uploaded)))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
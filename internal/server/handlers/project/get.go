@@ -0,0 +1,43 @@
+package project
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type GetProjectResponse struct {
+	Response resp.Response       `json:"response"`
+	Project  *repository.Project `json:"project"`
+}
+
+func GetProjectHandler(policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.project.GetProjectHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "projectID")
+
+		project, err := policy.ViewProject(r.Context(), mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", id)), err, "Project")
+			return
+		}
+
+		render.JSON(w, r, GetProjectResponse{
+			Response: resp.OK(),
+			Project:  project,
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package project
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/listquery"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+type ListProjectsResponse struct {
+	Response   resp.Response         `json:"response"`
+	Projects   []*repository.Project `json:"projects"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// ListProjectsHandler returns a page of the projects the authenticated user is a member of.
+// See listquery.Parse for the paging parameters; projects can be filtered by the user's role.
+func ListProjectsHandler(repo repository.Projects, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.project.ListProjectsHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		opts, err := listquery.Parse(r, "role")
+		if err != nil {
+			resp.RenderError(w, r, resp.BadRequest(err.Error()))
+			return
+		}
+
+		projects, next, err := repo.GetAllForUser(r.Context(), mwAuth.UserFromContext(r.Context()).ID, opts)
+		if listquery.RenderError(w, r, err) {
+			return
+		}
+		if err != nil {
+			log.Error("Failed to list projects", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to list projects"))
+			return
+		}
+		if projects == nil {
+			projects = []*repository.Project{}
+		}
+
+		render.JSON(w, r, ListProjectsResponse{
+			Response:   resp.OK(),
+			Projects:   projects,
+			NextCursor: next,
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package project
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type ListMembersResponse struct {
+	Response resp.Response               `json:"response"`
+	Members  []*repository.ProjectMember `json:"members"`
+}
+
+// ListMembersHandler returns the members of a project with their roles. Any member may list them.
+func ListMembersHandler(members repository.ProjectMembers, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.project.ListMembersHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "projectID")
+
+		if _, err := policy.ViewProject(r.Context(), mwAuth.UserFromContext(r.Context()), id); err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", id)), err, "Project")
+			return
+		}
+
+		list, err := members.GetAll(r.Context(), id)
+		if err != nil {
+			log.Error("Failed to list project members", "error", err, slog.String("project_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to list project members"))
+			return
+		}
+		if list == nil {
+			list = []*repository.ProjectMember{}
+		}
+
+		render.JSON(w, r, ListMembersResponse{
+			Response: resp.OK(),
+			Members:  list,
+		})
+	}
+}
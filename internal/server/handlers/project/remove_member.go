@@ -0,0 +1,60 @@
+package project
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// RemoveMemberHandler removes a user from a project. Owners may remove anyone and
+// members may leave on their own, but the last owner cannot be removed.
+func RemoveMemberHandler(members repository.ProjectMembers, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.project.RemoveMemberHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "projectID")
+		userID := chi.URLParam(r, "userID")
+
+		user := mwAuth.UserFromContext(r.Context())
+		check := policy.ManageProject
+		if userID == user.ID {
+			check = policy.ViewProject
+		}
+		if _, err := check(r.Context(), user, id); err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", id)), err, "Project")
+			return
+		}
+
+		err := members.Remove(r.Context(), id, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Project member not found"))
+			return
+		}
+		if errors.Is(err, repository.ErrLastOwner) {
+			resp.RenderError(w, r, resp.Conflict("A project must keep at least one owner"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to remove project member", "error", err, slog.String("project_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to remove project member"))
+			return
+		}
+
+		log.Info("Project member removed", slog.String("project_id", id), slog.String("user_id", userID))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,81 @@
+package project
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type SetMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+// SetMemberHandler adds a user to a project or changes their role. Only project owners
+// may do so, and the last owner cannot be demoted.
+func SetMemberHandler(members repository.ProjectMembers, users repository.Users, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.project.SetMemberHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "projectID")
+		userID := chi.URLParam(r, "userID")
+
+		var req SetMemberRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		if _, err := policy.ManageProject(r.Context(), mwAuth.UserFromContext(r.Context()), id); err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", id)), err, "Project")
+			return
+		}
+
+		member, err := users.GetByID(r.Context(), userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("User not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get user", "error", err, slog.String("user_id", userID))
+			resp.RenderError(w, r, resp.Internal("Failed to get user"))
+			return
+		}
+
+		err = members.SetRole(r.Context(), id, member.ID, req.Role)
+		if errors.Is(err, repository.ErrLastOwner) {
+			resp.RenderError(w, r, resp.Conflict("A project must keep at least one owner"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to set project member", "error", err, slog.String("project_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to set project member"))
+			return
+		}
+
+		log.Info("Project member set", slog.String("project_id", id), slog.String("user_id", member.ID), slog.String("role", req.Role))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,87 @@
+package project
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// UpdateProjectRequest is a partial update: only the fields present in the body are changed.
+type UpdateProjectRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Description *string `json:"description" validate:"omitempty,max=2000"`
+}
+
+type UpdateProjectResponse struct {
+	Response resp.Response       `json:"response"`
+	Project  *repository.Project `json:"project"`
+}
+
+// UpdateProjectHandler renames or redescribes a project. Only project owners may change it.
+func UpdateProjectHandler(repo repository.Projects, policy *access.Policy, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.project.UpdateProjectHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "projectID")
+
+		var req UpdateProjectRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+		log.Info("Request body decoded", slog.Any("request", req))
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		project, err := policy.ManageProject(r.Context(), mwAuth.UserFromContext(r.Context()), id)
+		if err != nil {
+			access.RenderError(w, r, log.With(slog.String("project_id", id)), err, "Project")
+			return
+		}
+
+		if req.Name != nil {
+			project.Name = *req.Name
+		}
+		if req.Description != nil {
+			project.Description = *req.Description
+		}
+
+		err = repo.Update(r.Context(), project)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to update project", "error", err, slog.String("project_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to update project"))
+			return
+		}
+
+		log.Info("Project updated successfully", slog.String("project_id", id))
+
+		render.JSON(w, r, UpdateProjectResponse{
+			Response: resp.OK(),
+			Project:  project,
+		})
+	}
+}
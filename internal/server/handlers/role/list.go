@@ -0,0 +1,43 @@
+package role
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+type ListRolesResponse struct {
+	Response resp.Response      `json:"response"`
+	Roles    []*repository.Role `json:"roles"`
+}
+
+// ListRolesHandler returns every role with the permissions it grants.
+func ListRolesHandler(repo repository.Roles, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.role.ListRolesHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		roles, err := repo.GetAll(r.Context())
+		if err != nil {
+			log.Error("Failed to list roles", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to list roles"))
+			return
+		}
+		if roles == nil {
+			roles = []*repository.Role{}
+		}
+
+		render.JSON(w, r, ListRolesResponse{
+			Response: resp.OK(),
+			Roles:    roles,
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package user
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 
 	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
 	"github.com/Agero19/AnnotateX-api/internal/lib/hash"
 	"github.com/Agero19/AnnotateX-api/internal/repository"
 	"github.com/go-chi/chi/middleware"
@@ -36,21 +39,25 @@ func CreateUserHandler(repo repository.Users, log *slog.Logger) http.HandlerFunc
 		var req CreateUserRequest
 		if err := render.DecodeJSON(r.Body, &req); err != nil {
 			log.Error("Failed to decode request", "error", err)
-			render.JSON(w, r, resp.Error("Failed to decode request"))
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
 			return
 		}
-		log.Info("Request body decoded", slog.Any("request", req))
+		log.Info(
+			"Request body decoded",
+			slog.String("username", req.Username),
+			slog.String("email", req.Email),
+		)
 
-		if err := validator.New().Struct(req); err != nil {
+		if err := validate.Struct(req); err != nil {
 			log.Error("Invalid request payload", "error", err)
-			render.JSON(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
 			return
 		}
 
 		hashedPwd, err := hash.HashPassword(req.Password)
 		if err != nil {
 			log.Error("Failed to hash password", "error", err)
-			render.JSON(w, r, resp.Error("Failed to hash password"))
+			resp.RenderError(w, r, resp.Internal("Failed to hash password"))
 			return
 		}
 
@@ -62,9 +69,22 @@ func CreateUserHandler(repo repository.Users, log *slog.Logger) http.HandlerFunc
 			CreatedAt: "",
 		}
 
-		if err := repo.Create(user); err != nil {
+		err = repo.Create(r.Context(), user)
+		var dup *repository.DuplicateError
+		if errors.As(err, &dup) {
+			log.Info("User already exists", slog.String("field", dup.Field))
+			conflict := resp.Conflict("User already exists")
+			conflict.Error.Details = []resp.FieldError{{
+				Field:   dup.Field,
+				Code:    "unique",
+				Message: fmt.Sprintf("Field '%s' is already taken", dup.Field),
+			}}
+			resp.RenderError(w, r, conflict)
+			return
+		}
+		if err != nil {
 			log.Error("Failed to create user", "error", err)
-			render.JSON(w, r, resp.Error("Failed to create user"))
+			resp.RenderError(w, r, resp.Internal("Failed to create user"))
 			return
 		}
 
@@ -80,6 +100,7 @@ func CreateUserHandler(repo repository.Users, log *slog.Logger) http.HandlerFunc
 			CreatedAt: user.CreatedAt,
 		}
 
+		render.Status(r, http.StatusCreated)
 		render.JSON(w, r, response)
 	}
 }
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+func DeleteUserHandler(repo repository.Users, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.user.DeleteUserHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "userID")
+
+		err := repo.Delete(r.Context(), id)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("User not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to delete user", "error", err, slog.String("user_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to delete user"))
+			return
+		}
+
+		log.Info("User deleted successfully", slog.String("user_id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
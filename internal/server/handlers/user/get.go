@@ -0,0 +1,55 @@
+package user
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type GetUserResponse struct {
+	Response resp.Response    `json:"response"`
+	User     *repository.User `json:"user"`
+}
+
+// GetUserHandler returns a user together with their roles.
+func GetUserHandler(repo repository.Users, roles repository.Roles, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.user.GetUserHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "userID")
+
+		user, err := repo.GetByID(r.Context(), id)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("User not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get user", "error", err, slog.String("user_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to get user"))
+			return
+		}
+
+		user.Roles, err = roles.GetByUserID(r.Context(), user.ID)
+		if err != nil {
+			log.Error("Failed to get user roles", "error", err, slog.String("user_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to get user"))
+			return
+		}
+
+		render.JSON(w, r, GetUserResponse{
+			Response: resp.OK(),
+			User:     user,
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/listquery"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+type ListUsersResponse struct {
+	Response   resp.Response      `json:"response"`
+	Users      []*repository.User `json:"users"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// ListUsersHandler returns a page of users. See listquery.Parse for the paging parameters;
+// users can be filtered by username and email.
+func ListUsersHandler(repo repository.Users, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.user.ListUsersHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		opts, err := listquery.Parse(r, "username", "email")
+		if err != nil {
+			resp.RenderError(w, r, resp.BadRequest(err.Error()))
+			return
+		}
+
+		users, next, err := repo.GetAll(r.Context(), opts)
+		if listquery.RenderError(w, r, err) {
+			return
+		}
+		if err != nil {
+			log.Error("Failed to list users", "error", err)
+			resp.RenderError(w, r, resp.Internal("Failed to list users"))
+			return
+		}
+		if users == nil {
+			users = []*repository.User{}
+		}
+
+		render.JSON(w, r, ListUsersResponse{
+			Response:   resp.OK(),
+			Users:      users,
+			NextCursor: next,
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package user
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/api/validate"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type SetRolesRequest struct {
+	Roles []string `json:"roles" validate:"required,dive,oneof=admin reviewer annotator"`
+}
+
+// SetRolesHandler replaces the roles of a user.
+func SetRolesHandler(repo repository.Users, roles repository.Roles, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.user.SetRolesHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := chi.URLParam(r, "userID")
+
+		var req SetRolesRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("Failed to decode request", "error", err)
+			resp.RenderError(w, r, resp.BadRequest("Failed to decode request"))
+			return
+		}
+		log.Info("Request body decoded", slog.Any("request", req))
+
+		if err := validate.Struct(req); err != nil {
+			log.Error("Invalid request payload", "error", err)
+			resp.RenderError(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		user, err := repo.GetByID(r.Context(), id)
+		if errors.Is(err, repository.ErrNotFound) {
+			resp.RenderError(w, r, resp.NotFound("User not found"))
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get user", "error", err, slog.String("user_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to get user"))
+			return
+		}
+
+		if err := roles.SetUserRoles(r.Context(), user.ID, req.Roles); err != nil {
+			log.Error("Failed to set user roles", "error", err, slog.String("user_id", id))
+			resp.RenderError(w, r, resp.Internal("Failed to set user roles"))
+			return
+		}
+		user.Roles = req.Roles
+
+		log.Info("User roles updated", slog.String("user_id", id), slog.Any("roles", req.Roles))
+
+		render.JSON(w, r, GetUserResponse{
+			Response: resp.OK(),
+			User:     user,
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package listquery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// Parse reads the options shared by list endpoints from the query string:
+// cursor, limit, sort (a field name, prefixed with "-" for descending order)
+// and the named filters. Other query parameters are ignored.
+func Parse(r *http.Request, filters ...string) (repository.ListOptions, error) {
+	q := r.URL.Query()
+
+	opts := repository.ListOptions{
+		Cursor: q.Get("cursor"),
+		Sort:   q.Get("sort"),
+	}
+	if strings.HasPrefix(opts.Sort, "-") {
+		opts.Sort = opts.Sort[1:]
+		opts.Desc = true
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > repository.MaxLimit {
+			return opts, errors.New("Query parameter 'limit' must be an integer between 1 and " + strconv.Itoa(repository.MaxLimit))
+		}
+		opts.Limit = n
+	}
+
+	for _, name := range filters {
+		if v := q.Get(name); v != "" {
+			if opts.Filters == nil {
+				opts.Filters = map[string]string{}
+			}
+			opts.Filters[name] = v
+		}
+	}
+	return opts, nil
+}
+
+// RenderError writes a 400 response if err rejects the list options and reports whether it did.
+func RenderError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var optsErr *repository.ListOptionsError
+	if !errors.As(err, &optsErr) {
+		return false
+	}
+	resp.RenderError(w, r, resp.BadRequest("Invalid list options: "+optsErr.Reason))
+	return true
+}
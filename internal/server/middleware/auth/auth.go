@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/token"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/go-chi/chi/middleware"
+)
+
+type ctxKey struct{}
+
+// New returns a middleware that requires a valid Bearer access token and
+// stores the authenticated user in the request context.
+func New(tokens *token.Manager, users repository.Users, log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(slog.String("component", "middleware/auth"))
+
+		log.Info("Auth middleware initialized")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			entry := log.With(slog.String("request_id", middleware.GetReqID(r.Context())))
+
+			raw, ok := bearerToken(r)
+			if !ok {
+				unauthorized(w, r, "Missing access token")
+				return
+			}
+
+			userID, err := tokens.ParseAccessToken(raw)
+			if err != nil {
+				entry.Info("Rejected access token", "error", err)
+				unauthorized(w, r, "Invalid access token")
+				return
+			}
+
+			user, err := users.GetByID(r.Context(), userID)
+			if errors.Is(err, repository.ErrNotFound) {
+				unauthorized(w, r, "Invalid access token")
+				return
+			}
+			if err != nil {
+				entry.Error("Failed to load authenticated user", "error", err, slog.String("user_id", userID))
+				resp.RenderError(w, r, resp.Internal("Failed to authenticate"))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, user *repository.User) context.Context {
+	return context.WithValue(ctx, ctxKey{}, user)
+}
+
+// UserFromContext returns the authenticated user, or nil if the request is anonymous.
+func UserFromContext(ctx context.Context) *repository.User {
+	user, _ := ctx.Value(ctxKey{}).(*repository.User)
+	return user
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, raw, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || raw == "" {
+		return "", false
+	}
+	return strings.TrimSpace(raw), true
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="annotatex"`)
+	resp.RenderError(w, r, resp.Unauthorized(msg))
+}
@@ -0,0 +1,50 @@
+package rbac
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
+	"github.com/go-chi/chi/middleware"
+)
+
+// RequirePermission returns a middleware that only lets through users whose roles grant the permission.
+// It must be mounted after the auth middleware.
+func RequirePermission(roles repository.Roles, log *slog.Logger, permission string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/rbac"),
+			slog.String("permission", permission),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			entry := log.With(slog.String("request_id", middleware.GetReqID(r.Context())))
+
+			user := mwAuth.UserFromContext(r.Context())
+			if user == nil {
+				resp.RenderError(w, r, resp.Unauthorized("Authentication required"))
+				return
+			}
+
+			permissions, err := roles.GetPermissions(r.Context(), user.ID)
+			if err != nil {
+				entry.Error("Failed to load permissions", "error", err, slog.String("user_id", user.ID))
+				resp.RenderError(w, r, resp.Internal("Failed to check permissions"))
+				return
+			}
+
+			if !slices.Contains(permissions, permission) {
+				entry.Info("Permission denied", slog.String("user_id", user.ID))
+				resp.RenderError(w, r, resp.Forbidden("Missing permission "+permission))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -1,15 +1,36 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Agero19/AnnotateX-api/internal/config"
+	resp "github.com/Agero19/AnnotateX-api/internal/lib/api/response"
+	"github.com/Agero19/AnnotateX-api/internal/lib/imagemeta"
+	"github.com/Agero19/AnnotateX-api/internal/lib/token"
 	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+	"github.com/Agero19/AnnotateX-api/internal/server/handlers/annotation"
+	"github.com/Agero19/AnnotateX-api/internal/server/handlers/auth"
+	"github.com/Agero19/AnnotateX-api/internal/server/handlers/export"
 	"github.com/Agero19/AnnotateX-api/internal/server/handlers/health"
+	"github.com/Agero19/AnnotateX-api/internal/server/handlers/image"
+	"github.com/Agero19/AnnotateX-api/internal/server/handlers/importer"
+	"github.com/Agero19/AnnotateX-api/internal/server/handlers/label"
+	"github.com/Agero19/AnnotateX-api/internal/server/handlers/project"
+	"github.com/Agero19/AnnotateX-api/internal/server/handlers/role"
 	"github.com/Agero19/AnnotateX-api/internal/server/handlers/user"
+	mwAuth "github.com/Agero19/AnnotateX-api/internal/server/middleware/auth"
 	mwLogger "github.com/Agero19/AnnotateX-api/internal/server/middleware/logger"
+	mwRBAC "github.com/Agero19/AnnotateX-api/internal/server/middleware/rbac"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+	"github.com/Agero19/AnnotateX-api/internal/thumbnail"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -19,14 +40,45 @@ type application struct {
 	Config config.Config
 	Repo   repository.Repository
 	Logger *slog.Logger
+	Tokens *token.Manager
+	Policy *access.Policy
+	Blob   storage.Blob
+	// Fetcher downloads registered image URLs for metadata extraction
+	Fetcher    *http.Client
+	Thumbnails *thumbnail.Worker
+
+	// background workers share a context that is cancelled on shutdown
+	workers     sync.WaitGroup
+	workerCtx   context.Context
+	stopWorkers context.CancelFunc
 }
 
-// NewApp creates a new application instance with the given configuration and repository.
-func NewApp(cfg *config.Config, repo repository.Repository, log *slog.Logger) *application {
+// NewApp creates a new application instance with the given configuration, repository and blob storage.
+func NewApp(cfg *config.Config, repo repository.Repository, blob storage.Blob, log *slog.Logger) *application {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+
 	return &application{
 		Config: *cfg,
 		Repo:   repo,
 		Logger: log,
+		Tokens: token.NewManager(
+			cfg.Auth.Secret,
+			cfg.Auth.Issuer,
+			cfg.Auth.AccessTTL,
+			cfg.Auth.RefreshTTL,
+		),
+		Policy:  access.NewPolicy(repo.Images, repo.Annotations, repo.ImageMembers, repo.Projects, repo.ProjectMembers),
+		Blob:    blob,
+		Fetcher: imagemeta.NewClient(cfg.Storage.FetchTimeout),
+		Thumbnails: thumbnail.NewWorker(
+			repo.Images,
+			blob,
+			log,
+			cfg.Thumbnails.PollInterval,
+			cfg.Thumbnails.BatchSize,
+		),
+		workerCtx:   workerCtx,
+		stopWorkers: stopWorkers,
 	}
 }
 
@@ -40,18 +92,206 @@ func (app *application) Mount() http.Handler {
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.URLFormat)
 
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		resp.RenderError(w, r, resp.NotFound("Route not found"))
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		resp.RenderError(w, r, resp.Error(resp.CodeMethod, "Method not allowed"))
+	})
+
+	authenticate := mwAuth.New(app.Tokens, app.Repo.Users, app.Logger)
+	// every request is bounded by the request timeout except dataset imports and exports,
+	// which stream large bodies and get the longer transfer deadline instead
+	timeout := middleware.Timeout(app.Config.HTTP.RequestTimeout)
+
+	r.Group(func(r chi.Router) {
+		r.Use(timeout)
+
+		// Probes for the orchestrator, outside the versioned API
+		r.Get("/livez", health.LivenessHandler(app.Logger))
+		r.Get("/readyz", health.ReadinessHandler(app.readinessChecks(), app.Config.HTTP.HealthCheckTimeout, app.Logger))
+
+		// The local storage backend has no server of its own, so its files are served here
+		if local, ok := app.Blob.(*storage.Local); ok && strings.HasPrefix(app.Config.Storage.PublicURL, "/") {
+			prefix := strings.TrimSuffix(app.Config.Storage.PublicURL, "/")
+			r.Handle(prefix+"/*", http.StripPrefix(prefix, http.FileServer(http.Dir(local.Root()))))
+		}
+	})
+
 	// Mount routes here
 	r.Route("/v1", func(r chi.Router) {
-		r.Get("/health", health.HealthCheckHandler(app.Logger))
-		r.Route("/users", func(r chi.Router) {
-			r.Post("/", user.CreateUserHandler(app.Repo.Users, app.Logger))
+		r.Group(func(r chi.Router) {
+			r.Use(timeout)
+
+			r.Get("/health", health.HealthCheckHandler(app.Logger))
+			r.Route("/users", func(r chi.Router) {
+				r.Post("/", user.CreateUserHandler(app.Repo.Users, app.Logger))
+				r.Group(func(r chi.Router) {
+					r.Use(authenticate)
+					r.With(app.requirePermission(repository.PermUsersRead)).Get("/", user.ListUsersHandler(app.Repo.Users, app.Logger))
+					r.With(app.requirePermission(repository.PermUsersRead)).Get("/{userID}", user.GetUserHandler(app.Repo.Users, app.Repo.Roles, app.Logger))
+					r.With(app.requirePermission(repository.PermUsersManage)).Delete("/{userID}", user.DeleteUserHandler(app.Repo.Users, app.Logger))
+					r.With(app.requirePermission(repository.PermUsersManage)).Put("/{userID}/roles", user.SetRolesHandler(app.Repo.Users, app.Repo.Roles, app.Logger))
+				})
+			})
+			r.Route("/auth", func(r chi.Router) {
+				r.Post("/login", auth.LoginHandler(app.Repo.Users, app.Repo.RefreshTokens, app.Tokens, app.Logger))
+				r.Post("/refresh", auth.RefreshHandler(app.Repo.RefreshTokens, app.Tokens, app.Logger))
+				r.Post("/logout", auth.LogoutHandler(app.Repo.RefreshTokens, app.Logger))
+			})
+		})
+
+		// Routes below require a valid access token
+		r.Group(func(r chi.Router) {
+			r.Use(authenticate)
+
+			r.Group(func(r chi.Router) {
+				r.Use(timeout)
+
+				r.With(app.requirePermission(repository.PermUsersRead)).Get("/roles", role.ListRolesHandler(app.Repo.Roles, app.Logger))
+				r.Route("/labels/{labelID}", func(r chi.Router) {
+					r.Patch("/", label.UpdateLabelHandler(app.Repo.Labels, app.Policy, app.Logger))
+					r.Post("/merge", label.MergeLabelHandler(app.Repo.Labels, app.Policy, app.Logger))
+					r.Post("/archive", label.ArchiveLabelHandler(app.Repo.Labels, app.Policy, true, app.Logger))
+					r.Post("/restore", label.ArchiveLabelHandler(app.Repo.Labels, app.Policy, false, app.Logger))
+				})
+			})
+
+			r.Route("/images", func(r chi.Router) {
+				r.Group(func(r chi.Router) {
+					r.Use(timeout)
+
+					r.Post("/", image.CreateImageHandler(app.Repo.Images, app.Fetcher, app.Config.Storage.MaxUploadSize, app.Config.Storage.MaxPixels, app.Policy, app.Logger))
+					r.Get("/", image.ListImagesHandler(app.Repo.Images, app.Policy, app.Logger))
+					r.Get("/duplicates", image.ListDuplicatesHandler(app.Repo.Images, app.Policy, app.Logger))
+					r.Route("/{imageID}", func(r chi.Router) {
+						r.Get("/", image.GetImageHandler(app.Policy, app.Logger))
+						r.Patch("/", image.UpdateImageHandler(app.Repo.Images, app.Fetcher, app.Config.Storage.MaxUploadSize, app.Config.Storage.MaxPixels, app.Policy, app.Logger))
+						r.Delete("/", image.DeleteImageHandler(app.Repo.Images, app.Blob, app.Policy, app.Logger))
+						r.Post("/members", image.AddMemberHandler(app.Repo.ImageMembers, app.Repo.Users, app.Policy, app.Logger))
+						r.Delete("/members/{userID}", image.RemoveMemberHandler(app.Repo.ImageMembers, app.Policy, app.Logger))
+						r.Get("/export/dota", export.ImageDOTAHandler(app.Repo.Annotations, app.Repo.Labels, app.Policy, app.Logger))
+						r.Get("/export/voc", export.ImageVOCHandler(app.Repo.Annotations, app.Repo.Labels, app.Policy, app.Logger))
+						r.Route("/annotations", func(r chi.Router) {
+							r.With(app.requirePermission(repository.PermAnnotationsWrite)).Post("/", annotation.CreateAnnotationHandler(app.Repo.Annotations, app.Repo.Labels, app.Policy, app.Logger))
+							r.Get("/", annotation.ListAnnotationsHandler(app.Repo.Annotations, app.Policy, app.Logger))
+						})
+					})
+				})
+
+				// File uploads
+				r.Group(func(r chi.Router) {
+					r.Use(app.transferDeadline)
+
+					r.Post("/upload", image.UploadImageHandler(app.Repo.Images, app.Blob, app.Thumbnails, app.Config.Storage.MaxUploadSize, app.Config.Storage.MaxPixels, app.Policy, app.Logger))
+					r.With(app.requirePermission(repository.PermAnnotationsWrite)).Post("/{imageID}/masks", annotation.CreateMaskHandler(app.Repo.Annotations, app.Repo.Labels, app.Config.Storage.MaxUploadSize, app.Policy, app.Logger))
+				})
+			})
+
+			r.Route("/annotations/{annotationID}", func(r chi.Router) {
+				r.Group(func(r chi.Router) {
+					r.Use(timeout)
+
+					r.Get("/", annotation.GetAnnotationHandler(app.Policy, app.Logger))
+					r.With(app.requirePermission(repository.PermAnnotationsWrite)).Patch("/", annotation.UpdateAnnotationHandler(app.Repo.Annotations, app.Repo.Labels, app.Policy, app.Logger))
+					r.With(app.requirePermission(repository.PermAnnotationsWrite)).Delete("/", annotation.DeleteAnnotationHandler(app.Repo.Annotations, app.Policy, app.Logger))
+					r.Get("/mask", annotation.GetMaskHandler(app.Repo.Annotations, app.Policy, app.Logger))
+					r.With(app.requirePermission(repository.PermAnnotationsReview)).Post("/review", annotation.ReviewAnnotationHandler(app.Repo.Annotations, app.Policy, app.Logger))
+				})
+
+				// File uploads
+				r.With(app.transferDeadline, app.requirePermission(repository.PermAnnotationsWrite)).Put("/mask", annotation.UpdateMaskHandler(app.Repo.Annotations, app.Repo.Labels, app.Config.Storage.MaxUploadSize, app.Policy, app.Logger))
+			})
+
+			r.Route("/projects", func(r chi.Router) {
+				r.With(timeout).Post("/", project.CreateProjectHandler(app.Repo.Projects, app.Logger))
+				r.With(timeout).Get("/", project.ListProjectsHandler(app.Repo.Projects, app.Logger))
+				r.Route("/{projectID}", func(r chi.Router) {
+					r.Group(func(r chi.Router) {
+						r.Use(timeout)
+
+						r.Get("/", project.GetProjectHandler(app.Policy, app.Logger))
+						r.Patch("/", project.UpdateProjectHandler(app.Repo.Projects, app.Policy, app.Logger))
+						r.Delete("/", project.DeleteProjectHandler(app.Repo.Projects, app.Repo.Images, app.Blob, app.Policy, app.Logger))
+						r.Get("/images", image.ListImagesHandler(app.Repo.Images, app.Policy, app.Logger))
+						r.Get("/duplicates", image.ListDuplicatesHandler(app.Repo.Images, app.Policy, app.Logger))
+						r.Get("/members", project.ListMembersHandler(app.Repo.ProjectMembers, app.Policy, app.Logger))
+						r.Put("/members/{userID}", project.SetMemberHandler(app.Repo.ProjectMembers, app.Repo.Users, app.Policy, app.Logger))
+						r.Delete("/members/{userID}", project.RemoveMemberHandler(app.Repo.ProjectMembers, app.Policy, app.Logger))
+						r.Post("/labels", label.CreateLabelHandler(app.Repo.Labels, app.Policy, app.Logger))
+						r.Get("/labels", label.ListLabelsHandler(app.Repo.Labels, app.Policy, app.Logger))
+					})
+
+					// Dataset transfers
+					r.Group(func(r chi.Router) {
+						r.Use(app.transferDeadline)
+
+						r.Get("/export/dota", export.ProjectDOTAHandler(app.Repo.Images, app.Repo.Annotations, app.Repo.Labels, app.Policy, app.Logger))
+						r.Get("/export/coco", export.ProjectCOCOHandler(app.Repo.Images, app.Repo.Annotations, app.Repo.Labels, app.Policy, app.Logger))
+						r.Get("/export/voc", export.ProjectVOCHandler(app.Repo.Images, app.Repo.Annotations, app.Repo.Labels, app.Policy, app.Logger))
+						r.With(app.requirePermission(repository.PermAnnotationsWrite)).Post("/import/coco", importer.ProjectCOCOImportHandler(app.Repo.Images, app.Repo.Annotations, app.Repo.Labels, app.Repo.Imports, app.Config.Dataset.MaxImportSize, app.Config.Storage.MaxPixels, app.Policy, app.Logger))
+						r.With(app.requirePermission(repository.PermAnnotationsWrite)).Post("/import/voc", importer.ProjectVOCImportHandler(app.Repo.Images, app.Repo.Labels, app.Repo.Imports, app.Config.Dataset.MaxImportSize, app.Policy, app.Logger))
+					})
+				})
+			})
 		})
 	})
 
 	return r
 }
 
-func (app *application) Run(mux http.Handler) error {
+// readinessChecks lists the dependencies that must be reachable for the server to take traffic.
+func (app *application) readinessChecks() []health.Check {
+	return []health.Check{
+		{Name: "postgres", Fn: app.Repo.Ping},
+		{Name: "storage", Fn: app.Blob.Ping},
+	}
+}
+
+// requirePermission guards a route with the given RBAC permission.
+func (app *application) requirePermission(permission string) func(http.Handler) http.Handler {
+	return mwRBAC.RequirePermission(app.Repo.Roles, app.Logger, permission)
+}
+
+// transferDeadline replaces the request timeout and the server's read and write timeouts
+// with the longer transfer timeout, for file uploads and dataset imports and exports whose
+// bodies take longer to send than an API call.
+func (app *application) transferDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline := time.Now().Add(app.Config.HTTP.TransferTimeout)
+
+		rc := http.NewResponseController(w)
+		if err := rc.SetReadDeadline(deadline); err != nil {
+			app.Logger.Warn("Failed to extend read deadline", "error", err, slog.String("request_id", middleware.GetReqID(r.Context())))
+		}
+		if err := rc.SetWriteDeadline(deadline); err != nil {
+			app.Logger.Warn("Failed to extend write deadline", "error", err, slog.String("request_id", middleware.GetReqID(r.Context())))
+		}
+
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Background runs fn in a goroutine tracked by the application. The context passed to fn
+// is cancelled when the server shuts down, and Run waits for fn to return before exiting.
+func (app *application) Background(name string, fn func(ctx context.Context)) {
+	app.workers.Add(1)
+	go func() {
+		defer app.workers.Done()
+		defer func() {
+			if rec := recover(); rec != nil {
+				app.Logger.Error("Background worker panicked", slog.String("worker", name), slog.Any("panic", rec))
+			}
+		}()
+		fn(app.workerCtx)
+	}()
+}
+
+// Run serves mux until ctx is cancelled, then stops accepting connections and gives
+// in-flight requests and background workers up to the configured shutdown timeout to finish.
+func (app *application) Run(ctx context.Context, mux http.Handler) error {
 
 	srv := &http.Server{
 		Addr:         app.Config.Port,
@@ -61,5 +301,51 @@ func (app *application) Run(mux http.Handler) error {
 		IdleTimeout:  time.Minute,
 	}
 
-	return srv.ListenAndServe()
+	serveErr := make(chan error, 1)
+	go func() {
+		app.Logger.Info("API server listening", slog.String("addr", srv.Addr))
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		// the server failed before a shutdown was requested
+		app.stopWorkers()
+		app.workers.Wait()
+		return err
+	case <-ctx.Done():
+	}
+
+	app.Logger.Info("Shutting down API server", slog.Duration("timeout", app.Config.HTTP.ShutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), app.Config.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	var shutdownErr error
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		shutdownErr = fmt.Errorf("server shutdown: %w", err)
+		// drop the connections that did not finish in time
+		_ = srv.Close()
+	}
+
+	app.stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		app.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		shutdownErr = errors.Join(shutdownErr, errors.New("background workers did not stop in time"))
+	}
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		shutdownErr = errors.Join(shutdownErr, err)
+	}
+
+	if shutdownErr == nil {
+		app.Logger.Info("API server stopped")
+	}
+	return shutdownErr
 }
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Agero19/AnnotateX-api/internal/config"
+)
+
+// Open creates the storage backend selected by the configuration.
+func Open(ctx context.Context, cfg *config.Config) (Blob, error) {
+	switch cfg.Storage.Backend {
+	case "local":
+		return NewLocal(cfg.Storage.LocalRoot, cfg.Storage.PublicURL)
+	case "s3":
+		return NewS3(ctx, S3Options{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			AccessKey: cfg.Storage.S3.AccessKey,
+			SecretKey: cfg.Storage.S3.SecretKey,
+			Bucket:    cfg.Storage.S3.Bucket,
+			Region:    cfg.Storage.S3.Region,
+			UseSSL:    cfg.Storage.S3.UseSSL,
+			PublicURL: cfg.Storage.PublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+	}
+}
@@ -0,0 +1,161 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path"
+	"strconv"
+	"strings"
+
+	// decoders for every format accepted on upload
+	_ "golang.org/x/image/webp"
+	_ "image/gif"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+	"golang.org/x/image/draw"
+)
+
+// Sizes are the lengths of the longest side of the generated thumbnails, largest first.
+var Sizes = []int{1024, 512, 128}
+
+const jpegQuality = 85
+
+// Generate decodes the stored original of an uploaded image and stores a scaled
+// copy for every entry of Sizes. Images smaller than a size are not upscaled.
+// Thumbnails are upright: the EXIF orientation of the original is applied.
+func Generate(ctx context.Context, blob storage.Blob, img *repository.Image) ([]repository.Thumbnail, error) {
+	const op = "thumbnail.Generate"
+
+	if img.ObjectKey == "" {
+		return nil, fmt.Errorf("%s: image %s has no stored file", op, img.ID)
+	}
+
+	rc, err := blob.Get(ctx, img.ObjectKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	src, _, err := image.Decode(rc)
+	rc.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode: %w", op, err)
+	}
+
+	orientation := 0
+	if img.Metadata != nil {
+		orientation = img.Metadata.Orientation
+	}
+
+	thumbnails := make([]repository.Thumbnail, 0, len(Sizes))
+	for _, size := range Sizes {
+		// each size is scaled from the previous, smaller one to keep the work proportional to the output
+		src = scale(src, size)
+		out := orient(src, orientation)
+
+		var buf bytes.Buffer
+		ext, contentType := ".png", "image/png"
+		if isOpaque(out) {
+			ext, contentType = ".jpg", "image/jpeg"
+			err = jpeg.Encode(&buf, out, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			err = png.Encode(&buf, out)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: encode: %w", op, err)
+		}
+
+		key := Key(img.ObjectKey, size, ext)
+		if err := blob.Put(ctx, key, &buf, int64(buf.Len()), contentType); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		b := out.Bounds()
+		thumbnails = append(thumbnails, repository.Thumbnail{
+			Size:      size,
+			Width:     b.Dx(),
+			Height:    b.Dy(),
+			URL:       blob.URL(key),
+			ObjectKey: key,
+		})
+	}
+	return thumbnails, nil
+}
+
+// Key returns the storage key of a thumbnail, e.g. images/ab/abcd.png -> thumbnails/ab/abcd_512.jpg.
+func Key(objectKey string, size int, ext string) string {
+	name := strings.TrimPrefix(objectKey, "images/")
+	name = strings.TrimSuffix(name, path.Ext(name))
+	return "thumbnails/" + name + "_" + strconv.Itoa(size) + ext
+}
+
+// scale returns src resized so its longest side is at most size.
+func scale(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return src
+	}
+	if w >= h {
+		h = max(1, h*size/w)
+		w = size
+	} else {
+		w = max(1, w*size/h)
+		h = size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8) so the image displays upright.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// orientations 5-8 are rotated by 90 degrees and swap the dimensions
+	transpose := orientation >= 5
+	dw, dh := w, h
+	if transpose {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored along the top-left diagonal
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // mirrored along the top-right diagonal
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+)
+
+// Worker generates thumbnails for uploaded images in the background.
+//
+// Pending images are read from the database, so uploads made while the worker
+// was down are not lost. Generation is idempotent: if several API instances
+// pick up the same image they write the same keys.
+type Worker struct {
+	images    repository.Images
+	blob      storage.Blob
+	log       *slog.Logger
+	interval  time.Duration
+	batchSize int
+	wake      chan struct{}
+}
+
+// NewWorker creates a worker that polls for pending images every interval, batchSize at a time.
+func NewWorker(images repository.Images, blob storage.Blob, log *slog.Logger, interval time.Duration, batchSize int) *Worker {
+	return &Worker{
+		images:    images,
+		blob:      blob,
+		log:       log.With(slog.String("worker", "thumbnails")),
+		interval:  interval,
+		batchSize: batchSize,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes the worker up without waiting for the next poll, e.g. after an upload.
+func (w *Worker) Notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+		// a wake-up is already queued
+	}
+}
+
+// Run processes pending images until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.ProcessPending(ctx); err != nil && ctx.Err() == nil {
+			w.log.Error("Failed to process pending thumbnails", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// ProcessPending generates thumbnails for pending images until none are left and
+// returns how many images were processed.
+func (w *Worker) ProcessPending(ctx context.Context) (int, error) {
+	const op = "thumbnail.Worker.ProcessPending"
+
+	processed := 0
+	for {
+		images, err := w.images.GetPendingThumbnails(ctx, w.batchSize)
+		if err != nil {
+			return processed, fmt.Errorf("%s: %w", op, err)
+		}
+		if len(images) == 0 {
+			return processed, nil
+		}
+
+		for _, image := range images {
+			if err := w.process(ctx, image); err != nil {
+				return processed, fmt.Errorf("%s: %w", op, err)
+			}
+			processed++
+		}
+	}
+}
+
+// process generates the thumbnails of one image. A failure to generate them marks
+// the image as failed so it is not retried forever; only failing to record the
+// outcome, or being cancelled, is returned as an error.
+func (w *Worker) process(ctx context.Context, image *repository.Image) error {
+	log := w.log.With(slog.String("image_id", image.ID))
+
+	thumbnails, err := Generate(ctx, w.blob, image)
+	if ctx.Err() != nil {
+		// leave the image pending for the next run
+		return ctx.Err()
+	}
+
+	status := repository.ThumbnailReady
+	if err != nil {
+		log.Error("Failed to generate thumbnails", "error", err)
+		status = repository.ThumbnailFailed
+	}
+
+	err = w.images.SetThumbnails(ctx, image.ID, status, thumbnails)
+	if errors.Is(err, repository.ErrNotFound) {
+		// the image was deleted meanwhile; its files go with it unless another image shares them
+		w.removeThumbnails(image.ObjectKey, thumbnails)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info("Thumbnails processed", slog.String("status", status), slog.Int("count", len(thumbnails)))
+	return nil
+}
+
+func (w *Worker) removeThumbnails(objectKey string, thumbnails []repository.Thumbnail) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	inUse, err := w.images.HasObjectKey(ctx, objectKey)
+	if err != nil {
+		w.log.Error("Failed to check stored file references", "error", err, slog.String("key", objectKey))
+		return
+	}
+	if inUse {
+		return
+	}
+
+	for _, t := range thumbnails {
+		if err := w.blob.Delete(ctx, t.ObjectKey); err != nil {
+			w.log.Error("Failed to delete thumbnail", "error", err, slog.String("key", t.ObjectKey))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+)
+
+func TestPolicy_PrivateImage(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "policyowner", Email: "policyowner@example.com", Password: "secretpassword"}
+	member := &repository.User{Username: "policymember", Email: "policymember@example.com", Password: "secretpassword"}
+	stranger := &repository.User{Username: "policystranger", Email: "policystranger@example.com", Password: "secretpassword"}
+	for _, u := range []*repository.User{owner, member, stranger} {
+		if err := repo.Users.Create(ctx, u); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		defer repo.Users.Delete(ctx, u.ID)
+	}
+
+	image := &repository.Image{UserID: owner.ID, URL: "https://example.com/private.png", Title: "private"}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	if err := repo.ImageMembers.Add(ctx, image.ID, member.ID); err != nil {
+		t.Fatalf("failed to add member: %v", err)
+	}
+
+	annotation := &repository.Annotation{ImageID: image.ID, UserID: member.ID, Width: 1, Height: 1}
+	if err := repo.Annotations.Create(ctx, annotation); err != nil {
+		t.Fatalf("failed to create annotation: %v", err)
+	}
+
+	policy := access.NewPolicy(repo.Images, repo.Annotations, repo.ImageMembers, repo.Projects, repo.ProjectMembers)
+
+	if _, err := policy.ViewImage(ctx, owner, image.ID); err != nil {
+		t.Errorf("expected owner to view image, got %v", err)
+	}
+	if _, err := policy.ViewImage(ctx, member, image.ID); err != nil {
+		t.Errorf("expected member to view image, got %v", err)
+	}
+	if _, err := policy.ViewImage(ctx, stranger, image.ID); !errors.Is(err, access.ErrNotFound) {
+		t.Errorf("expected stranger to get not found, got %v", err)
+	}
+	if _, err := policy.ManageImage(ctx, member, image.ID); !errors.Is(err, access.ErrForbidden) {
+		t.Errorf("expected member to be forbidden from managing image, got %v", err)
+	}
+	if _, _, err := policy.EditAnnotation(ctx, member, annotation.ID); err != nil {
+		t.Errorf("expected author to edit annotation, got %v", err)
+	}
+	if _, _, err := policy.EditAnnotation(ctx, owner, annotation.ID); err != nil {
+		t.Errorf("expected image owner to edit annotation, got %v", err)
+	}
+
+	visible, _, err := repo.Images.GetAllVisibleTo(ctx, stranger.ID, repository.ListOptions{Limit: repository.MaxLimit})
+	if err != nil {
+		t.Fatalf("failed to list visible images: %v", err)
+	}
+	for _, img := range visible {
+		if img.ID == image.ID {
+			t.Error("expected private image to be hidden from stranger")
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+func TestAnnotationRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "annotator",
+		Email:    "annotator@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	image := &repository.Image{
+		UserID: owner.ID,
+		URL:    "https://example.com/street.jpg",
+		Title:  "street",
+	}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	var createdAnnotation repository.Annotation
+
+	t.Run("Create", func(t *testing.T) {
+		annotation := &repository.Annotation{
+			ImageID: image.ID,
+			UserID:  owner.ID,
+			X:       10,
+			Y:       20,
+			Width:   30,
+			Height:  40,
+			Comment: "car",
+		}
+		if err := repo.Annotations.Create(ctx, annotation); err != nil {
+			t.Fatalf("failed to create annotation: %v", err)
+		}
+		if annotation.ID == "" {
+			t.Error("expected annotation ID to be set")
+		}
+		createdAnnotation = *annotation
+	})
+
+	t.Run("GetByImageID", func(t *testing.T) {
+		annotations, _, err := repo.Annotations.GetByImageID(ctx, image.ID, repository.ListOptions{})
+		if err != nil {
+			t.Fatalf("failed to get annotations by image: %v", err)
+		}
+		if len(annotations) != 1 || annotations[0].ID != createdAnnotation.ID {
+			t.Errorf("expected exactly annotation %s, got %+v", createdAnnotation.ID, annotations)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		createdAnnotation.Width = 50
+		createdAnnotation.Comment = "truck"
+
+		if err := repo.Annotations.Update(ctx, &createdAnnotation); err != nil {
+			t.Fatalf("failed to update annotation: %v", err)
+		}
+
+		updated, _ := repo.Annotations.GetByID(ctx, createdAnnotation.ID)
+		if updated.Width != 50 || updated.Comment != "truck" {
+			t.Errorf("expected annotation to be updated, got %+v", updated)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := repo.Annotations.Delete(ctx, createdAnnotation.ID); err != nil {
+			t.Fatalf("failed to delete annotation: %v", err)
+		}
+
+		deleted, _ := repo.Annotations.GetByID(ctx, createdAnnotation.ID)
+		if deleted != nil {
+			t.Errorf("expected annotation to be deleted")
+		}
+	})
+}
+
+func TestAnnotationRepository_Pagination(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "pager",
+		Email:    "pager@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	image := &repository.Image{
+		UserID: owner.ID,
+		URL:    "https://example.com/crowd.jpg",
+		Title:  "crowd",
+	}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	created := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		annotation := &repository.Annotation{ImageID: image.ID, UserID: owner.ID, X: float64(i), Width: 1, Height: 1}
+		if err := repo.Annotations.Create(ctx, annotation); err != nil {
+			t.Fatalf("failed to create annotation: %v", err)
+		}
+		created[annotation.ID] = true
+	}
+
+	for _, sort := range []string{"id", "created_at"} {
+		t.Run("Sort "+sort, func(t *testing.T) {
+			opts := repository.ListOptions{Limit: 2, Sort: sort, Desc: true}
+			seen := map[string]bool{}
+			var prev *repository.Annotation
+			for pages := 0; ; pages++ {
+				if pages > 3 {
+					t.Fatal("expected 3 pages")
+				}
+				page, next, err := repo.Annotations.GetByImageID(ctx, image.ID, opts)
+				if err != nil {
+					t.Fatalf("failed to list annotations: %v", err)
+				}
+				for _, a := range page {
+					if seen[a.ID] {
+						t.Errorf("annotation %s returned twice", a.ID)
+					}
+					if prev != nil && sort == "id" && atoi(a.ID) >= atoi(prev.ID) {
+						t.Errorf("expected descending ids, got %s after %s", a.ID, prev.ID)
+					}
+					seen[a.ID] = true
+					prev = a
+				}
+				if next == "" {
+					break
+				}
+				opts.Cursor = next
+			}
+			if len(seen) != len(created) {
+				t.Errorf("expected %d annotations, got %d", len(created), len(seen))
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, _, err := repo.Annotations.GetByImageID(ctx, image.ID, repository.ListOptions{Sort: "comment"})
+		if !errors.Is(err, repository.ErrInvalidListOptions) {
+			t.Errorf("expected ErrInvalidListOptions for an unknown sort field, got %v", err)
+		}
+
+		_, next, err := repo.Annotations.GetByImageID(ctx, image.ID, repository.ListOptions{Limit: 1})
+		if err != nil || next == "" {
+			t.Fatalf("expected a next page, got %q, %v", next, err)
+		}
+		_, _, err = repo.Annotations.GetByImageID(ctx, image.ID, repository.ListOptions{Cursor: next, Desc: true})
+		if !errors.Is(err, repository.ErrInvalidListOptions) {
+			t.Errorf("expected a cursor from another sort order to be rejected, got %v", err)
+		}
+
+		_, _, err = repo.Annotations.GetByImageID(ctx, image.ID, repository.ListOptions{Filters: map[string]string{"user_id": "abc"}})
+		if !errors.Is(err, repository.ErrInvalidListOptions) {
+			t.Errorf("expected a malformed filter value to be rejected, got %v", err)
+		}
+	})
+}
+
+func TestAnnotationRepository_Geometry(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "segmenter",
+		Email:    "segmenter@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	image := &repository.Image{UserID: owner.ID, URL: "https://example.com/field.jpg", Title: "field"}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	box := &repository.Annotation{ImageID: image.ID, UserID: owner.ID, X: 0.25, Y: 1.5, Width: 5.125, Height: 5}
+	polygon := &repository.Annotation{
+		ImageID: image.ID,
+		UserID:  owner.ID,
+		Geometry: &geometry.Geometry{
+			Type:   geometry.Polygon,
+			Points: []geometry.Vertex{{0, 0}, {10, 0}, {10, 10}},
+		},
+		X: 0, Y: 0, Width: 10, Height: 10,
+	}
+	for _, a := range []*repository.Annotation{box, polygon} {
+		if err := repo.Annotations.Create(ctx, a); err != nil {
+			t.Fatalf("failed to create annotation: %v", err)
+		}
+	}
+
+	got, err := repo.Annotations.GetByID(ctx, box.ID)
+	if err != nil {
+		t.Fatalf("failed to get box: %v", err)
+	}
+	if got.Geometry == nil || got.Geometry.Type != geometry.Box {
+		t.Errorf("expected a box geometry, got %+v", got.Geometry)
+	}
+	if got.X != 0.25 || got.Y != 1.5 || got.Width != 5.125 {
+		t.Errorf("expected sub-pixel coordinates to round-trip, got (%g, %g, %g)", got.X, got.Y, got.Width)
+	}
+
+	got, err = repo.Annotations.GetByID(ctx, polygon.ID)
+	if err != nil {
+		t.Fatalf("failed to get polygon: %v", err)
+	}
+	if got.Geometry.Type != geometry.Polygon || len(got.Geometry.Points) != 3 || got.Geometry.Points[1] != (geometry.Vertex{10, 0}) {
+		t.Errorf("expected the polygon to round-trip, got %+v", got.Geometry)
+	}
+
+	polygons, _, err := repo.Annotations.GetByImageID(ctx, image.ID, repository.ListOptions{Filters: map[string]string{"type": geometry.Polygon}})
+	if err != nil {
+		t.Fatalf("failed to filter by type: %v", err)
+	}
+	if len(polygons) != 1 || polygons[0].ID != polygon.ID {
+		t.Errorf("expected only the polygon, got %d annotations", len(polygons))
+	}
+}
+
+func TestAnnotationRepository_Mask(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "masker",
+		Email:    "masker@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	image := &repository.Image{UserID: owner.ID, URL: "https://example.com/cells.png", Title: "cells"}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	// a 3x4 mask with five foreground pixels spanning columns 1 to 3
+	mask := &rle.RLE{Height: 3, Width: 4, Counts: []uint32{4, 2, 1, 3, 2}}
+	annotation := &repository.Annotation{ImageID: image.ID, UserID: owner.ID}
+	if err := repo.Annotations.CreateMask(ctx, annotation, mask); err != nil {
+		t.Fatalf("failed to create mask annotation: %v", err)
+	}
+
+	got, err := repo.Annotations.GetByID(ctx, annotation.ID)
+	if err != nil {
+		t.Fatalf("failed to get annotation: %v", err)
+	}
+	if got.Geometry.Type != geometry.Mask || got.Geometry.Area != 5 {
+		t.Errorf("expected a mask of area 5, got %+v", got.Geometry)
+	}
+	if got.X != 1 || got.Y != 0 || got.Width != 3 || got.Height != 3 {
+		t.Errorf("expected bounding box (1, 0, 3, 3), got (%g, %g, %g, %g)", got.X, got.Y, got.Width, got.Height)
+	}
+
+	stored, err := repo.Annotations.GetMask(ctx, annotation.ID)
+	if err != nil {
+		t.Fatalf("failed to get mask: %v", err)
+	}
+	if stored.String() != mask.String() || stored.Height != 3 || stored.Width != 4 {
+		t.Errorf("expected mask %q to round-trip, got %q", mask.String(), stored.String())
+	}
+
+	smaller := &rle.RLE{Height: 3, Width: 4, Counts: []uint32{4, 1, 7}}
+	if err := repo.Annotations.UpdateMask(ctx, got, smaller); err != nil {
+		t.Fatalf("failed to update mask: %v", err)
+	}
+	if got.Geometry.Area != 1 || got.X != 1 || got.Y != 1 || got.Width != 1 || got.Height != 1 {
+		t.Errorf("expected a single pixel at (1, 1), got %+v at (%g, %g)", got.Geometry, got.X, got.Y)
+	}
+
+	if _, err := repo.Annotations.GetMask(ctx, "999999"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing mask, got %v", err)
+	}
+
+	// deleting the annotation removes its pixels
+	if err := repo.Annotations.Delete(ctx, annotation.ID); err != nil {
+		t.Fatalf("failed to delete annotation: %v", err)
+	}
+	if _, err := repo.Annotations.GetMask(ctx, annotation.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected the mask to be deleted with its annotation, got %v", err)
+	}
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
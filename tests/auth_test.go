@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/token"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+func TestTokenManager_AccessToken(t *testing.T) {
+	m := token.NewManager("secret", "annotatex-test", time.Minute, time.Hour)
+
+	raw, err := m.NewAccessToken("42")
+	if err != nil {
+		t.Fatalf("failed to sign access token: %v", err)
+	}
+
+	userID, err := m.ParseAccessToken(raw)
+	if err != nil {
+		t.Fatalf("failed to parse access token: %v", err)
+	}
+	if userID != "42" {
+		t.Errorf("expected subject 42, got %s", userID)
+	}
+
+	other := token.NewManager("other-secret", "annotatex-test", time.Minute, time.Hour)
+	if _, err := other.ParseAccessToken(raw); err == nil {
+		t.Error("expected token signed with another secret to be rejected")
+	}
+
+	expired := token.NewManager("secret", "annotatex-test", -time.Minute, time.Hour)
+	raw, _ = expired.NewAccessToken("42")
+	if _, err := m.ParseAccessToken(raw); err == nil {
+		t.Error("expected expired token to be rejected")
+	}
+}
+
+func TestRefreshTokenRepository_Rotation(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "sessionowner",
+		Email:    "sessionowner@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	raw, hashed, err := token.NewRefreshToken()
+	if err != nil {
+		t.Fatalf("failed to generate refresh token: %v", err)
+	}
+	if err := repo.RefreshTokens.Create(ctx, &repository.RefreshToken{
+		UserID:    owner.ID,
+		TokenHash: hashed,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to store refresh token: %v", err)
+	}
+
+	stored, err := repo.RefreshTokens.GetByHash(ctx, token.HashRefreshToken(raw))
+	if err != nil || stored == nil {
+		t.Fatalf("expected to find stored token, got %+v, %v", stored, err)
+	}
+
+	revoked, err := repo.RefreshTokens.Revoke(ctx, stored.ID)
+	if err != nil || !revoked {
+		t.Fatalf("expected first revoke to succeed, got %v, %v", revoked, err)
+	}
+
+	revoked, err = repo.RefreshTokens.Revoke(ctx, stored.ID)
+	if err != nil || revoked {
+		t.Errorf("expected second revoke to report reuse, got %v, %v", revoked, err)
+	}
+}
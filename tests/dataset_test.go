@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+func TestWriteDOTA(t *testing.T) {
+	annotations := []*repository.Annotation{
+		{LabelID: "1", X: 10, Y: 10, Width: 4, Height: 2, Geometry: &geometry.Geometry{Type: geometry.Box, Angle: 90}},
+		{X: 1.5, Y: 2, Width: 3, Height: 4, Geometry: &geometry.Geometry{Type: geometry.Box}, Attributes: map[string]any{"difficult": true}},
+		{LabelID: "1", Geometry: &geometry.Geometry{Type: geometry.Point, Points: []geometry.Vertex{{1, 1}}}},
+	}
+
+	var out strings.Builder
+	if err := dataset.WriteDOTA(&out, annotations, map[string]string{"1": "small vehicle"}); err != nil {
+		t.Fatalf("failed to write DOTA: %v", err)
+	}
+
+	want := "13 9 13 13 11 13 11 9 small-vehicle 0\n" +
+		"1.5 2 4.5 2 4.5 6 1.5 6 unlabeled 1\n"
+	if out.String() != want {
+		t.Errorf("unexpected DOTA output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestWriteVOC(t *testing.T) {
+	image := &repository.Image{ID: "7", Title: "street.jpg", Metadata: &repository.ImageMetadata{Width: 20, Height: 10, ColorModel: "ycbcr"}}
+	annotations := []*repository.Annotation{
+		{LabelID: "1", X: 2, Y: 3, Width: 4, Height: 5, Attributes: map[string]any{"difficult": true, "truncated": false, "pose": "Left"}},
+		{X: 10, Y: 5, Width: 4, Height: 2, Geometry: &geometry.Geometry{Type: geometry.Box, Angle: 90}, Attributes: map[string]any{"occluded": "1"}},
+		{LabelID: "1", X: 18, Y: 0, Width: 4, Height: 4, Geometry: &geometry.Geometry{Type: geometry.Mask, Area: 8}},
+		{LabelID: "1", Geometry: &geometry.Geometry{Type: geometry.Point, Points: []geometry.Vertex{{1, 1}}}},
+	}
+
+	var out bytes.Buffer
+	if err := dataset.WriteVOC(&out, image, annotations, map[string]string{"1": "car"}); err != nil {
+		t.Fatalf("failed to write VOC: %v", err)
+	}
+
+	var voc dataset.VOC
+	if err := xml.Unmarshal(out.Bytes(), &voc); err != nil {
+		t.Fatalf("failed to decode VOC: %v\n%s", err, out.String())
+	}
+	if voc.Filename != "street.jpg" || voc.Size != (dataset.VOCSize{Width: 20, Height: 10, Depth: 3}) {
+		t.Errorf("unexpected image entry: %+v", voc)
+	}
+	if len(voc.Objects) != 3 {
+		t.Fatalf("expected the point to be skipped, got %d objects", len(voc.Objects))
+	}
+
+	car := voc.Objects[0]
+	if car.Name != "car" || car.Pose != "Left" || car.Difficult != 1 || car.Truncated != 0 || car.Occluded != nil {
+		t.Errorf("unexpected object: %+v", car)
+	}
+	if car.BndBox != (dataset.VOCBox{XMin: 3, YMin: 4, XMax: 6, YMax: 8}) {
+		t.Errorf("expected 1-based inclusive corners, got %+v", car.BndBox)
+	}
+	// the rotated 4x2 box stands upright around its center at (12, 6)
+	rotated := voc.Objects[1]
+	if rotated.Name != dataset.Unlabeled || rotated.Pose != dataset.VOCUnspecifiedPose || rotated.Occluded == nil || *rotated.Occluded != 1 {
+		t.Errorf("unexpected object: %+v", rotated)
+	}
+	if rotated.BndBox != (dataset.VOCBox{XMin: 12, YMin: 5, XMax: 13, YMax: 8}) {
+		t.Errorf("expected the box around the rotated corners, got %+v", rotated.BndBox)
+	}
+	if mask := voc.Objects[2].BndBox; mask != (dataset.VOCBox{XMin: 19, YMin: 1, XMax: 20, YMax: 4}) {
+		t.Errorf("expected the mask box clipped to the image, got %+v", mask)
+	}
+}
+
+func TestCOCOWriter(t *testing.T) {
+	labels := []*repository.Label{
+		{ID: "1", Name: "animal"},
+		{ID: "2", Name: "dog", ParentID: "1"},
+	}
+	image := &repository.Image{ID: "7", Title: "park.jpg", URL: "https://example.com/park.jpg", Metadata: &repository.ImageMetadata{Width: 640, Height: 480}}
+	mask := &rle.RLE{Height: 480, Width: 640, Counts: []uint32{4, 2, 307194}}
+	annotations := []*repository.Annotation{
+		{ID: "10", LabelID: "2", X: 1, Y: 2, Width: 3, Height: 4},
+		{ID: "11", LabelID: "2", X: 0, Y: 0, Width: 4, Height: 4, Geometry: &geometry.Geometry{
+			Type:   geometry.Polygon,
+			Points: []geometry.Vertex{{0, 0}, {4, 0}, {0, 4}},
+		}},
+		{ID: "12", X: 0, Y: 4, Width: 1, Height: 2, Geometry: &geometry.Geometry{Type: geometry.Mask, Area: 2}},
+		{ID: "13", LabelID: "1", X: 5, Y: 5, Width: 1, Height: 1, Geometry: &geometry.Geometry{
+			Type:      geometry.Keypoints,
+			Keypoints: []geometry.Keypoint{{Name: "nose", X: 5, Y: 5, V: geometry.Visible}},
+		}},
+		{ID: "14", LabelID: "1", X: 6, Y: 6, Width: 1, Height: 1, Geometry: &geometry.Geometry{
+			Type: geometry.Keypoints,
+			Keypoints: []geometry.Keypoint{
+				{Name: "tail", X: 7, Y: 7, V: geometry.Occluded},
+				{Name: "nose", X: 6, Y: 6, V: geometry.Visible},
+			},
+		}},
+		{ID: "15", LabelID: "1", Geometry: &geometry.Geometry{Type: geometry.Polyline, Points: []geometry.Vertex{{0, 0}, {1, 1}}}},
+	}
+
+	var out bytes.Buffer
+	cw, err := dataset.NewCOCOWriter(&out, dataset.COCOInfo{Description: "pets"}, labels)
+	if err != nil {
+		t.Fatalf("failed to start COCO writer: %v", err)
+	}
+	defer cw.Close()
+	if err := cw.AddImage(image, annotations, map[string]*rle.RLE{"12": mask}); err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+	if err := cw.Finish(); err != nil {
+		t.Fatalf("failed to finish COCO export: %v", err)
+	}
+
+	var coco dataset.COCO
+	if err := json.Unmarshal(out.Bytes(), &coco); err != nil {
+		t.Fatalf("export is not valid JSON: %v\n%s", err, out.String())
+	}
+
+	if len(coco.Images) != 1 || coco.Images[0].ID != 7 || coco.Images[0].FileName != "park.jpg" || coco.Images[0].Width != 640 {
+		t.Errorf("unexpected images: %+v", coco.Images)
+	}
+	if len(coco.Categories) != 3 || coco.Categories[1].Supercategory != "animal" || coco.Categories[2].ID != dataset.COCOUnlabeledID {
+		t.Errorf("expected animal, dog under animal and unlabeled, got %+v", coco.Categories)
+	}
+	if got := coco.Categories[0].Keypoints; !slices.Equal(got, []string{"nose", "tail"}) {
+		t.Errorf("expected keypoints [nose tail], got %v", got)
+	}
+
+	// the polyline has no COCO representation
+	if len(coco.Annotations) != 5 {
+		t.Fatalf("expected 5 annotations, got %d", len(coco.Annotations))
+	}
+	box, polygon, masked, first, second := coco.Annotations[0], coco.Annotations[1], coco.Annotations[2], coco.Annotations[3], coco.Annotations[4]
+	if box.CategoryID != 2 || box.BBox != [4]float64{1, 2, 3, 4} || box.Area != 12 || len(box.Segmentation.Polygons) != 0 {
+		t.Errorf("unexpected box: %+v", box)
+	}
+	if polygon.Area != 8 || len(polygon.Segmentation.Polygons) != 1 || !slices.Equal(polygon.Segmentation.Polygons[0], []float64{0, 0, 4, 0, 0, 4}) {
+		t.Errorf("unexpected polygon: %+v", polygon)
+	}
+	if masked.CategoryID != dataset.COCOUnlabeledID || masked.Area != 2 || masked.Segmentation.RLE == nil || masked.Segmentation.RLE.String() != mask.String() {
+		t.Errorf("unexpected mask: %+v", masked)
+	}
+	// keypoints follow the category's order, with the ones an annotation lacks unlabeled
+	if !slices.Equal(first.Keypoints, []float64{5, 5, 2, 0, 0, 0}) || first.NumKeypoints != 1 {
+		t.Errorf("unexpected keypoints %v (%d labeled)", first.Keypoints, first.NumKeypoints)
+	}
+	if !slices.Equal(second.Keypoints, []float64{6, 6, 2, 7, 7, 1}) || second.NumKeypoints != 2 {
+		t.Errorf("unexpected keypoints %v (%d labeled)", second.Keypoints, second.NumKeypoints)
+	}
+}
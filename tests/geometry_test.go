@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+)
+
+func TestGeometry_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		g     geometry.Geometry
+		valid bool
+	}{
+		{"box", geometry.Geometry{Type: geometry.Box}, true},
+		{"rotated box", geometry.Geometry{Type: geometry.Box, Angle: -30}, true},
+		{"overturned box", geometry.Geometry{Type: geometry.Box, Angle: 270}, false},
+		{"rotated polygon", geometry.Geometry{Type: geometry.Polygon, Angle: 10, Points: []geometry.Vertex{{0, 0}, {10, 0}, {0, 10}}}, false},
+		{"box with points", geometry.Geometry{Type: geometry.Box, Points: []geometry.Vertex{{1, 1}}}, false},
+		{"triangle", geometry.Geometry{Type: geometry.Polygon, Points: []geometry.Vertex{{0, 0}, {10, 0}, {0, 10}}}, true},
+		{"two point polygon", geometry.Geometry{Type: geometry.Polygon, Points: []geometry.Vertex{{0, 0}, {10, 0}}}, false},
+		{"polyline", geometry.Geometry{Type: geometry.Polyline, Points: []geometry.Vertex{{0, 0}, {10, 0}}}, true},
+		{"point", geometry.Geometry{Type: geometry.Point, Points: []geometry.Vertex{{5, 5}}}, true},
+		{"two points", geometry.Geometry{Type: geometry.Point, Points: []geometry.Vertex{{5, 5}, {6, 6}}}, false},
+		{"negative", geometry.Geometry{Type: geometry.Point, Points: []geometry.Vertex{{-1, 5}}}, false},
+		{"keypoints", geometry.Geometry{Type: geometry.Keypoints, Keypoints: []geometry.Keypoint{
+			{Name: "nose", X: 5, Y: 5, V: geometry.Visible},
+			{Name: "ear", V: geometry.NotLabeled},
+		}}, true},
+		{"duplicate keypoint", geometry.Geometry{Type: geometry.Keypoints, Keypoints: []geometry.Keypoint{
+			{Name: "nose", X: 5, Y: 5, V: geometry.Visible},
+			{Name: "nose", X: 6, Y: 6, V: geometry.Visible},
+		}}, false},
+		{"unlabeled only", geometry.Geometry{Type: geometry.Keypoints, Keypoints: []geometry.Keypoint{{Name: "nose"}}}, false},
+		{"mask", geometry.Geometry{Type: geometry.Mask, Area: 12}, true},
+		{"mask with points", geometry.Geometry{Type: geometry.Mask, Points: []geometry.Vertex{{1, 1}}}, false},
+		{"unknown type", geometry.Geometry{Type: "circle"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.g.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("expected valid geometry, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestGeometry_Bounds(t *testing.T) {
+	polygon := geometry.Geometry{Type: geometry.Polygon, Points: []geometry.Vertex{{4, 2}, {10, 8}, {6, 12}}}
+	x, y, w, h, ok := polygon.Bounds()
+	if !ok || x != 4 || y != 2 || w != 6 || h != 10 {
+		t.Errorf("expected bounds (4, 2, 6, 10), got (%g, %g, %g, %g, %v)", x, y, w, h, ok)
+	}
+
+	// unlabeled keypoints have no position and must not stretch the box to the origin
+	pose := geometry.Geometry{Type: geometry.Keypoints, Keypoints: []geometry.Keypoint{
+		{Name: "left", X: 20, Y: 30, V: geometry.Visible},
+		{Name: "right", X: 40, Y: 35, V: geometry.Occluded},
+		{Name: "top", V: geometry.NotLabeled},
+	}}
+	x, y, w, h, ok = pose.Bounds()
+	if !ok || x != 20 || y != 30 || w != 20 || h != 5 {
+		t.Errorf("expected bounds (20, 30, 20, 5), got (%g, %g, %g, %g, %v)", x, y, w, h, ok)
+	}
+
+	box := geometry.Geometry{Type: geometry.Box}
+	if _, _, _, _, ok := box.Bounds(); ok {
+		t.Error("expected a box to report no bounds")
+	}
+}
+
+func TestGeometry_Scale(t *testing.T) {
+	g := geometry.Geometry{
+		Type:   geometry.Polyline,
+		Points: []geometry.Vertex{{100, 50}, {300.5, 150}},
+	}
+	g.Scale(1.0/400, 1.0/200)
+	if g.Points[0] != (geometry.Vertex{0.25, 0.25}) || g.Points[1][0] != 300.5/400 || g.Points[1][1] != 0.75 {
+		t.Errorf("expected normalized points, got %v", g.Points)
+	}
+}
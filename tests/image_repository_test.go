@@ -0,0 +1,382 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+func TestImageRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "imageowner",
+		Email:    "imageowner@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	var createdImage repository.Image
+
+	t.Run("Create", func(t *testing.T) {
+		image := &repository.Image{
+			UserID:      owner.ID,
+			URL:         "https://example.com/cat.png",
+			Title:       "cat",
+			Description: "a cat",
+			Visibility:  true,
+		}
+		if err := repo.Images.Create(ctx, image); err != nil {
+			t.Fatalf("failed to create image: %v", err)
+		}
+		if image.ID == "" {
+			t.Error("expected image ID to be set")
+		}
+		createdImage = *image
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		images, _, err := repo.Images.GetAll(ctx, repository.ListOptions{})
+		if err != nil {
+			t.Fatalf("failed to get all images: %v", err)
+		}
+		if len(images) == 0 {
+			t.Error("expected at least one image")
+		}
+	})
+
+	t.Run("GetByID", func(t *testing.T) {
+		image, err := repo.Images.GetByID(ctx, createdImage.ID)
+		if err != nil {
+			t.Fatalf("failed to get image by id: %v", err)
+		}
+		if image == nil || image.ID != createdImage.ID {
+			t.Errorf("expected to find image %s, got %+v", createdImage.ID, image)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		createdImage.Title = "dog"
+		createdImage.Visibility = false
+
+		if err := repo.Images.Update(ctx, &createdImage); err != nil {
+			t.Fatalf("failed to update image: %v", err)
+		}
+
+		updated, _ := repo.Images.GetByID(ctx, createdImage.ID)
+		if updated.Title != "dog" || updated.Visibility {
+			t.Errorf("expected image to be updated, got %+v", updated)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := repo.Images.Delete(ctx, createdImage.ID); err != nil {
+			t.Fatalf("failed to delete image: %v", err)
+		}
+
+		deleted, _ := repo.Images.GetByID(ctx, createdImage.ID)
+		if deleted != nil {
+			t.Errorf("expected image to be deleted")
+		}
+	})
+}
+
+func TestImageRepository_Metadata(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "metaowner",
+		Email:    "metaowner@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	captured := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	image := &repository.Image{
+		UserID:     owner.ID,
+		URL:        "https://example.com/wide.jpg",
+		Title:      "wide",
+		Visibility: true,
+		Metadata: &repository.ImageMetadata{
+			Width:       4000,
+			Height:      3000,
+			MimeType:    "image/jpeg",
+			SizeBytes:   123456,
+			ColorModel:  "ycbcr",
+			CapturedAt:  &captured,
+			CameraMake:  "Canon",
+			Orientation: 6,
+		},
+	}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	got, err := repo.Images.GetByID(ctx, image.ID)
+	if err != nil {
+		t.Fatalf("failed to get image: %v", err)
+	}
+	if got.Metadata == nil || got.Metadata.Width != 4000 || got.Metadata.Orientation != 6 || got.Metadata.CameraModel != "" {
+		t.Fatalf("expected metadata to round-trip, got %+v", got.Metadata)
+	}
+	if got.Metadata.CapturedAt == nil || !got.Metadata.CapturedAt.Equal(captured) {
+		t.Errorf("expected capture time %v, got %v", captured, got.Metadata.CapturedAt)
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		images, _, err := repo.Images.GetAllVisibleTo(ctx, owner.ID, repository.ListOptions{
+			Filters: map[string]string{"mime_type": "image/jpeg", "min_width": "3000"},
+		})
+		if err != nil {
+			t.Fatalf("failed to filter images: %v", err)
+		}
+		if !containsImage(images, image.ID) {
+			t.Error("expected the image to match the filter")
+		}
+
+		images, _, err = repo.Images.GetAllVisibleTo(ctx, owner.ID, repository.ListOptions{
+			Filters: map[string]string{"max_height": "1000"},
+		})
+		if err != nil {
+			t.Fatalf("failed to filter images: %v", err)
+		}
+		if containsImage(images, image.ID) {
+			t.Error("expected the image to be filtered out")
+		}
+	})
+}
+
+func containsImage(images []*repository.Image, id string) bool {
+	for _, image := range images {
+		if image.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestImageRepository_Thumbnails(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "thumbowner",
+		Email:    "thumbowner@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	image := &repository.Image{
+		UserID:          owner.ID,
+		URL:             "/files/images/th/thumbtest.png",
+		Title:           "upload",
+		ObjectKey:       "images/th/thumbtest.png",
+		ThumbnailStatus: repository.ThumbnailPending,
+	}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	pending, err := repo.Images.GetPendingThumbnails(ctx, 100)
+	if err != nil {
+		t.Fatalf("failed to get pending images: %v", err)
+	}
+	if !containsImage(pending, image.ID) {
+		t.Fatal("expected the upload to be pending")
+	}
+
+	thumbnails := []repository.Thumbnail{{Size: 128, Width: 128, Height: 64, URL: "/files/t.jpg", ObjectKey: "t.jpg"}}
+	if err := repo.Images.SetThumbnails(ctx, image.ID, repository.ThumbnailReady, thumbnails); err != nil {
+		t.Fatalf("failed to set thumbnails: %v", err)
+	}
+
+	got, err := repo.Images.GetByID(ctx, image.ID)
+	if err != nil {
+		t.Fatalf("failed to get image: %v", err)
+	}
+	if got.ThumbnailStatus != repository.ThumbnailReady || len(got.Thumbnails) != 1 || got.Thumbnails[0] != thumbnails[0] {
+		t.Errorf("expected thumbnails to round-trip, got %s %+v", got.ThumbnailStatus, got.Thumbnails)
+	}
+
+	// only images without ready thumbnails are queued unless all is set
+	if _, err := repo.Images.ResetThumbnails(ctx, false); err != nil {
+		t.Fatalf("failed to reset thumbnails: %v", err)
+	}
+	got, _ = repo.Images.GetByID(ctx, image.ID)
+	if got.ThumbnailStatus != repository.ThumbnailReady {
+		t.Errorf("expected ready thumbnails to be kept, got %s", got.ThumbnailStatus)
+	}
+
+	if _, err := repo.Images.ResetThumbnails(ctx, true); err != nil {
+		t.Fatalf("failed to reset thumbnails: %v", err)
+	}
+	got, _ = repo.Images.GetByID(ctx, image.ID)
+	if got.ThumbnailStatus != repository.ThumbnailPending {
+		t.Errorf("expected the image to be queued again, got %s", got.ThumbnailStatus)
+	}
+}
+
+func TestImageRepository_ContentHash(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "hashowner",
+		Email:    "hashowner@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	hash := strings.Repeat("ab", 32)
+	key := "images/ab/" + hash + ".png"
+	newImage := func() *repository.Image {
+		return &repository.Image{
+			UserID:      owner.ID,
+			URL:         "/files/" + key,
+			Title:       "upload",
+			ObjectKey:   key,
+			ContentHash: hash,
+		}
+	}
+
+	image := newImage()
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	found, err := repo.Images.GetByContentHash(ctx, owner.ID, "", hash)
+	if err != nil || found.ID != image.ID {
+		t.Fatalf("expected to find image %s by hash, got %+v, %v", image.ID, found, err)
+	}
+
+	err = repo.Images.Create(ctx, newImage())
+	var dup *repository.DuplicateError
+	if !errors.As(err, &dup) || dup.Field != "content_hash" {
+		t.Errorf("expected a content_hash duplicate error, got %v", err)
+	}
+
+	inUse, err := repo.Images.HasObjectKey(ctx, key)
+	if err != nil || !inUse {
+		t.Errorf("expected the object key to be in use, got %v, %v", inUse, err)
+	}
+
+	if err := repo.Images.Delete(ctx, image.ID); err != nil {
+		t.Fatalf("failed to delete image: %v", err)
+	}
+	if inUse, _ := repo.Images.HasObjectKey(ctx, key); inUse {
+		t.Error("expected the object key to be unreferenced after delete")
+	}
+}
+
+func TestImageRepository_PerceptualHashes(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{
+		Username: "phashowner",
+		Email:    "phashowner@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	// the high bit exercises the unsigned to BIGINT conversion
+	image := &repository.Image{
+		UserID:         owner.ID,
+		URL:            "https://example.com/p.png",
+		Title:          "p",
+		PerceptualHash: "f00000000000000f",
+	}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	hashes, err := repo.Images.GetPerceptualHashes(ctx, owner.ID, "")
+	if err != nil {
+		t.Fatalf("failed to get perceptual hashes: %v", err)
+	}
+	if len(hashes) != 1 || hashes[0].ImageID != image.ID || hashes[0].PerceptualHash != image.PerceptualHash {
+		t.Errorf("expected the hash to round-trip, got %+v", hashes)
+	}
+
+	image.PerceptualHash = "zz"
+	if err := repo.Images.Update(ctx, image); err == nil {
+		t.Error("expected an invalid hash to be rejected")
+	}
+}
+
+func TestImageRepository_MoveRemapsLabels(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "moveowner", Email: "moveowner@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	from := &repository.Project{Name: "from", CreatedBy: owner.ID}
+	to := &repository.Project{Name: "to", CreatedBy: owner.ID}
+	for _, p := range []*repository.Project{from, to} {
+		if err := repo.Projects.Create(ctx, p); err != nil {
+			t.Fatalf("failed to create project: %v", err)
+		}
+		defer repo.Projects.Delete(ctx, p.ID)
+	}
+
+	car := &repository.Label{ProjectID: from.ID, Name: "car", Color: "#ff0000"}
+	truck := &repository.Label{ProjectID: from.ID, Name: "truck", Color: "#00ff00"}
+	target := &repository.Label{ProjectID: to.ID, Name: "car", Color: "#0000ff"}
+	for _, l := range []*repository.Label{car, truck, target} {
+		if err := repo.Labels.Create(ctx, l); err != nil {
+			t.Fatalf("failed to create label: %v", err)
+		}
+	}
+
+	image := &repository.Image{UserID: owner.ID, ProjectID: from.ID, URL: "https://example.com/move.png", Title: "move"}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	defer repo.Images.Delete(ctx, image.ID)
+
+	labeled := &repository.Annotation{ImageID: image.ID, UserID: owner.ID, LabelID: car.ID, Width: 1, Height: 1}
+	unknown := &repository.Annotation{ImageID: image.ID, UserID: owner.ID, LabelID: truck.ID, Width: 1, Height: 1}
+	for _, a := range []*repository.Annotation{labeled, unknown} {
+		if err := repo.Annotations.Create(ctx, a); err != nil {
+			t.Fatalf("failed to create annotation: %v", err)
+		}
+	}
+
+	image.ProjectID = to.ID
+	if err := repo.Images.Update(ctx, image); err != nil {
+		t.Fatalf("failed to move image: %v", err)
+	}
+	if got, _ := repo.Annotations.GetByID(ctx, labeled.ID); got == nil || got.LabelID != target.ID {
+		t.Errorf("expected the car annotation to take the new project's car label, got %+v", got)
+	}
+	if got, _ := repo.Annotations.GetByID(ctx, unknown.ID); got == nil || got.LabelID != "" {
+		t.Errorf("expected the truck label to be cleared, got %+v", got)
+	}
+
+	image.ProjectID = ""
+	if err := repo.Images.Update(ctx, image); err != nil {
+		t.Fatalf("failed to move image out of its project: %v", err)
+	}
+	if got, _ := repo.Annotations.GetByID(ctx, labeled.ID); got == nil || got.LabelID != "" {
+		t.Errorf("expected labels to be cleared outside a project, got %+v", got)
+	}
+}
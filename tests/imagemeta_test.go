@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/imagemeta"
+)
+
+func TestImagemetaExtract(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 128})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+
+	meta, err := imagemeta.Extract(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 100)
+	if err != nil {
+		t.Fatalf("failed to extract metadata: %v", err)
+	}
+	if meta.Width != 3 || meta.Height != 2 {
+		t.Errorf("expected 3x2, got %dx%d", meta.Width, meta.Height)
+	}
+	if meta.MimeType != "image/png" || meta.ColorModel != "nrgba" || meta.Size != int64(buf.Len()) {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+	if meta.CapturedAt != nil || meta.Orientation != 0 {
+		t.Errorf("expected no EXIF fields, got %+v", meta)
+	}
+
+	_, err = imagemeta.Extract(bytes.NewReader([]byte("plain text")), 10, 100)
+	if !errors.Is(err, imagemeta.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestImagemetaExtract_TooManyPixels(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 20, 10))); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+
+	// refused from the header, before the pixels are decoded
+	_, err := imagemeta.Extract(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 199)
+	if !errors.Is(err, imagemeta.ErrTooManyPixels) {
+		t.Errorf("expected ErrTooManyPixels, got %v", err)
+	}
+	if _, err := imagemeta.Extract(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 200); err != nil {
+		t.Errorf("expected an image of exactly the limit to pass, got %v", err)
+	}
+}
+
+func TestImagemetaFetch_InternalAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected no request to reach the loopback server")
+	}))
+	defer srv.Close()
+
+	client := imagemeta.NewClient(time.Second)
+	for _, url := range []string{
+		srv.URL + "/image.png",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]:1/image.png",
+		"http://10.0.0.1/image.png",
+	} {
+		_, err := imagemeta.Fetch(context.Background(), client, url, 1<<20, 100)
+		if !errors.Is(err, imagemeta.ErrForbiddenAddress) {
+			t.Errorf("expected %s to be refused, got %v", url, err)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package tests
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+// stubImages serves a fixed list of project images to the import planners.
+type stubImages struct {
+	repository.Images
+	images []*repository.Image
+}
+
+func (s stubImages) GetAll(ctx context.Context, opts repository.ListOptions) ([]*repository.Image, string, error) {
+	return s.images, "", nil
+}
+
+// stubAnnotations serves a fixed list of annotations on the project's images to the import planners.
+type stubAnnotations struct {
+	repository.Annotations
+	annotations []*repository.Annotation
+}
+
+func (s stubAnnotations) GetByImageIDs(ctx context.Context, imageIDs []string) ([]*repository.Annotation, error) {
+	return s.annotations, nil
+}
+
+// stubLabels serves a fixed list of project labels to the import planners.
+type stubLabels struct {
+	repository.Labels
+	labels []*repository.Label
+}
+
+func (s stubLabels) GetByProjectID(ctx context.Context, projectID string, archived bool) ([]*repository.Label, error) {
+	return s.labels, nil
+}
+
+func conflictCodes(report dataset.ImportReport) []string {
+	codes := []string{}
+	for _, c := range report.Conflicts {
+		codes = append(codes, c.Code)
+	}
+	return codes
+}
+
+func TestPlanCOCOImport(t *testing.T) {
+	project := &repository.Project{ID: "1"}
+	sized := []dataset.COCOImage{{ID: 1, FileName: "a.jpg", Width: 10, Height: 10}}
+	existing := []*repository.Image{{ID: "5", ProjectID: "1", URL: "https://example.com/a.jpg", Title: "a.jpg"}}
+	car := []dataset.COCOCategory{{ID: 1, Name: "car"}}
+	box := [4]float64{1, 1, 2, 2}
+
+	tests := []struct {
+		name        string
+		coco        dataset.COCO
+		images      []*repository.Image
+		labels      []*repository.Label
+		onImages    []*repository.Annotation
+		conflicts   []string
+		annotations int
+	}{
+		{
+			name:        "valid box",
+			coco:        dataset.COCO{Images: sized, Categories: car, Annotations: []dataset.COCOAnnotation{{ID: 1, ImageID: 1, CategoryID: 1, BBox: box}}},
+			images:      existing,
+			conflicts:   []string{},
+			annotations: 1,
+		},
+		{
+			name: "duplicate annotation id",
+			coco: dataset.COCO{Images: sized, Categories: car, Annotations: []dataset.COCOAnnotation{
+				{ID: 1, ImageID: 1, CategoryID: 1, BBox: box},
+				{ID: 1, ImageID: 1, CategoryID: 1, BBox: box},
+			}},
+			images:      existing,
+			conflicts:   []string{"duplicate_id"},
+			annotations: 1,
+		},
+		{
+			name:      "duplicate category id",
+			coco:      dataset.COCO{Images: sized, Categories: []dataset.COCOCategory{{ID: 1, Name: "car"}, {ID: 1, Name: "bus"}}},
+			images:    existing,
+			conflicts: []string{"duplicate_id"},
+		},
+		{
+			name: "ambiguous file name",
+			coco: dataset.COCO{Images: sized, Categories: car, Annotations: []dataset.COCOAnnotation{{ID: 1, ImageID: 1, CategoryID: 1, BBox: box}}},
+			images: []*repository.Image{
+				{ID: "5", ProjectID: "1", URL: "https://example.com/1/a.jpg", Title: "a.jpg"},
+				{ID: "6", ProjectID: "1", URL: "https://example.com/2/a.jpg", Title: "a.jpg"},
+			},
+			conflicts: []string{"ambiguous_image"},
+		},
+		{
+			name: "supercategory cycle",
+			coco: dataset.COCO{Categories: []dataset.COCOCategory{
+				{ID: 1, Name: "car", Supercategory: "vehicle"},
+				{ID: 2, Name: "vehicle", Supercategory: "car"},
+			}},
+			conflicts: []string{"label_cycle", "label_cycle"},
+		},
+		{
+			name:      "supercategory of an existing label",
+			coco:      dataset.COCO{Categories: []dataset.COCOCategory{{ID: 1, Name: "car", Supercategory: "vehicle"}, {ID: 2, Name: "vehicle", Supercategory: "car"}}},
+			labels:    []*repository.Label{{ID: "3", ProjectID: "1", Name: "vehicle"}},
+			conflicts: []string{},
+		},
+		{
+			name:      "bbox outside the image",
+			coco:      dataset.COCO{Images: sized, Categories: car, Annotations: []dataset.COCOAnnotation{{ID: 1, ImageID: 1, CategoryID: 1, BBox: [4]float64{5, 5, 20, 1}}}},
+			images:    existing,
+			conflicts: []string{"invalid_annotation"},
+		},
+		{
+			name: "polygon outside the image",
+			coco: dataset.COCO{Images: sized, Categories: car, Annotations: []dataset.COCOAnnotation{{
+				ID: 1, ImageID: 1, CategoryID: 1, BBox: box,
+				Segmentation: dataset.COCOSegmentation{Polygons: [][]float64{{0, 0, 30, 0, 0, 5}}},
+			}}},
+			images:    existing,
+			conflicts: []string{"invalid_annotation"},
+		},
+		{
+			name: "keypoint count mismatch",
+			coco: dataset.COCO{
+				Images:      sized,
+				Categories:  []dataset.COCOCategory{{ID: 1, Name: "person", Keypoints: []string{"nose", "left_eye"}}},
+				Annotations: []dataset.COCOAnnotation{{ID: 1, ImageID: 1, CategoryID: 1, BBox: box, Keypoints: []float64{1, 1, 2}}},
+			},
+			images:    existing,
+			conflicts: []string{"invalid_annotation"},
+		},
+		{
+			name: "mask beyond the pixel limit on an image of unknown size",
+			coco: dataset.COCO{
+				Images:     []dataset.COCOImage{{ID: 1, FileName: "a.jpg"}},
+				Categories: car,
+				Annotations: []dataset.COCOAnnotation{{
+					ID: 1, ImageID: 1, CategoryID: 1, BBox: box,
+					Segmentation: dataset.COCOSegmentation{RLE: &rle.RLE{Height: 20_000, Width: 20_000, Counts: []uint32{0, 400_000_000}}},
+				}},
+			},
+			images:    existing,
+			conflicts: []string{"invalid_annotation"},
+		},
+		{
+			name: "mask beyond the pixel limit on an image of declared size",
+			coco: dataset.COCO{
+				Images:     []dataset.COCOImage{{ID: 1, FileName: "a.jpg", Width: 20_000, Height: 20_000}},
+				Categories: car,
+				Annotations: []dataset.COCOAnnotation{{
+					ID: 1, ImageID: 1, CategoryID: 1, BBox: box,
+					Segmentation: dataset.COCOSegmentation{RLE: &rle.RLE{Height: 20_000, Width: 20_000, Counts: []uint32{0, 400_000_000}}},
+				}},
+			},
+			images:    existing,
+			conflicts: []string{"invalid_annotation"},
+		},
+		{
+			name:      "registered image beyond the pixel limit",
+			coco:      dataset.COCO{Images: []dataset.COCOImage{{ID: 1, FileName: "b.jpg", URL: "https://example.com/b.jpg", Width: 2_000, Height: 1_000}}},
+			conflicts: []string{"too_many_pixels"},
+		},
+		{
+			name:        "annotation already on the image",
+			coco:        dataset.COCO{Images: sized, Categories: car, Annotations: []dataset.COCOAnnotation{{ID: 1, ImageID: 1, CategoryID: 1, BBox: box}, {ID: 2, ImageID: 1, CategoryID: 1, BBox: [4]float64{3, 3, 2, 2}}}},
+			images:      existing,
+			labels:      []*repository.Label{{ID: "3", ProjectID: "1", Name: "car"}},
+			onImages:    []*repository.Annotation{{ImageID: "5", LabelID: "3", X: 1, Y: 1, Width: 2, Height: 2, Geometry: &geometry.Geometry{Type: geometry.Box}}},
+			conflicts:   []string{"duplicate_annotation"},
+			annotations: 1,
+		},
+		{
+			name:      "unknown image and category",
+			coco:      dataset.COCO{Images: sized, Categories: car, Annotations: []dataset.COCOAnnotation{{ID: 1, ImageID: 2, CategoryID: 1, BBox: box}, {ID: 2, ImageID: 1, CategoryID: 9, BBox: box}}},
+			images:    existing,
+			conflicts: []string{"unknown_image", "unknown_category"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := dataset.PlanCOCOImport(context.Background(), &tt.coco, project, "1", 1_000_000, stubImages{images: tt.images}, stubAnnotations{annotations: tt.onImages}, stubLabels{labels: tt.labels})
+			if err != nil {
+				t.Fatalf("failed to plan import: %v", err)
+			}
+			if codes := conflictCodes(plan.Report); !slices.Equal(codes, tt.conflicts) {
+				t.Errorf("expected conflicts %v, got %v", tt.conflicts, codes)
+			}
+			if plan.Report.Annotations != tt.annotations {
+				t.Errorf("expected %d annotations, got %d", tt.annotations, plan.Report.Annotations)
+			}
+		})
+	}
+}
+
+func TestPlanCOCOImport_ClampsOverhang(t *testing.T) {
+	coco := dataset.COCO{
+		Images:     []dataset.COCOImage{{ID: 1, FileName: "a.jpg", Width: 10, Height: 10}},
+		Categories: []dataset.COCOCategory{{ID: 1, Name: "car"}},
+		Annotations: []dataset.COCOAnnotation{
+			{ID: 1, ImageID: 1, CategoryID: 1, BBox: [4]float64{2, 3, 8.4, 7.5}},
+			{ID: 2, ImageID: 1, CategoryID: 1, BBox: [4]float64{1, 1, 2, 2}, Segmentation: dataset.COCOSegmentation{Polygons: [][]float64{{1, 1, 10.5, 1, 1, 10.2}}}},
+		},
+	}
+	images := stubImages{images: []*repository.Image{{ID: "5", ProjectID: "1", Title: "a.jpg"}}}
+
+	plan, err := dataset.PlanCOCOImport(context.Background(), &coco, &repository.Project{ID: "1"}, "1", 1_000_000, images, stubAnnotations{}, stubLabels{})
+	if err != nil {
+		t.Fatalf("failed to plan import: %v", err)
+	}
+	if plan.Report.ConflictCount != 0 || len(plan.Batch.Annotations) != 2 {
+		t.Fatalf("expected both shapes to be imported, got %+v", plan.Report)
+	}
+	box := plan.Batch.Annotations[0].Annotation
+	if box.X+box.Width != 10 || box.Y+box.Height != 10 {
+		t.Errorf("expected the box to be clamped to the image, got %+v", box)
+	}
+	polygon := plan.Batch.Annotations[1].Annotation
+	if polygon.Width != 9 || polygon.Height != 9 || polygon.Geometry.Points[1][0] != 10 {
+		t.Errorf("expected the polygon to be clamped to the image, got %+v", polygon)
+	}
+}
+
+func TestPlanVOCImport(t *testing.T) {
+	project := &repository.Project{ID: "1"}
+	existing := []*repository.Image{{ID: "5", ProjectID: "1", Title: "a.jpg", Metadata: &repository.ImageMetadata{Width: 100, Height: 80}}}
+	object := func(name, box string) string {
+		return "<object><name>" + name + "</name><bndbox>" + box + "</bndbox></object>"
+	}
+	file := func(filename string, objects ...string) string {
+		s := "<annotation><filename>" + filename + "</filename>"
+		for _, o := range objects {
+			s += o
+		}
+		return s + "</annotation>"
+	}
+	inside := "<xmin>1</xmin><ymin>1</ymin><xmax>10</xmax><ymax>10</ymax>"
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		images      []*repository.Image
+		conflicts   []string
+		annotations int
+		unmatched   []string
+	}{
+		{
+			name:        "valid box",
+			files:       map[string]string{"a.xml": file("a.jpg", object("dog", inside))},
+			images:      existing,
+			conflicts:   []string{},
+			annotations: 1,
+			unmatched:   []string{},
+		},
+		{
+			name: "image annotated twice",
+			files: map[string]string{
+				"a.xml":       file("a.jpg", object("dog", inside)),
+				"other/a.xml": file("a.jpg", object("dog", inside)),
+			},
+			images:      existing,
+			conflicts:   []string{"duplicate_image"},
+			annotations: 1,
+			unmatched:   []string{},
+		},
+		{
+			name:  "ambiguous file name",
+			files: map[string]string{"a.xml": file("a.jpg", object("dog", inside))},
+			images: []*repository.Image{
+				{ID: "5", ProjectID: "1", Title: "a.jpg"},
+				{ID: "6", ProjectID: "1", Title: "a.jpg"},
+			},
+			conflicts: []string{"ambiguous_image"},
+			unmatched: []string{},
+		},
+		{
+			name:      "bndbox outside the image",
+			files:     map[string]string{"a.xml": file("a.jpg", object("dog", "<xmin>50</xmin><ymin>1</ymin><xmax>120</xmax><ymax>10</ymax>"))},
+			images:    existing,
+			conflicts: []string{"invalid_annotation"},
+			unmatched: []string{},
+		},
+		{
+			name:      "inverted bndbox",
+			files:     map[string]string{"a.xml": file("a.jpg", object("dog", "<xmin>10</xmin><ymin>1</ymin><xmax>5</xmax><ymax>10</ymax>"))},
+			images:    existing,
+			conflicts: []string{"invalid_annotation"},
+			unmatched: []string{},
+		},
+		{
+			name:      "missing object name",
+			files:     map[string]string{"a.xml": file("a.jpg", object("", inside))},
+			images:    existing,
+			conflicts: []string{"invalid_name"},
+			unmatched: []string{},
+		},
+		{
+			name:      "invalid file",
+			files:     map[string]string{"a.xml": "<annotation><object>", "b.xml": "<annotation></annotation>"},
+			images:    existing,
+			conflicts: []string{"invalid_file", "invalid_file"},
+			unmatched: []string{},
+		},
+		{
+			name:      "unmatched image",
+			files:     map[string]string{"b.xml": file("b.jpg", object("dog", inside), object("cat", inside))},
+			images:    existing,
+			conflicts: []string{},
+			unmatched: []string{"b.jpg"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := dataset.PlanVOCImport(context.Background(), vocArchive(t, tt.files), project, "1", stubImages{images: tt.images}, stubLabels{})
+			if err != nil {
+				t.Fatalf("failed to plan import: %v", err)
+			}
+			if codes := conflictCodes(plan.Report); !slices.Equal(codes, tt.conflicts) {
+				t.Errorf("expected conflicts %v, got %v", tt.conflicts, codes)
+			}
+			if plan.Report.Annotations != tt.annotations {
+				t.Errorf("expected %d annotations, got %d", tt.annotations, plan.Report.Annotations)
+			}
+			if !slices.Equal(plan.Report.UnmatchedImages, tt.unmatched) {
+				t.Errorf("expected unmatched images %v, got %v", tt.unmatched, plan.Report.UnmatchedImages)
+			}
+		})
+	}
+}
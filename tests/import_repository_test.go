@@ -0,0 +1,361 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/dataset"
+	"github.com/Agero19/AnnotateX-api/internal/lib/geometry"
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+)
+
+const cocoFixture = `{
+	"images": [
+		{"id": 1, "file_name": "a.jpg", "width": 100, "height": 100},
+		{"id": 2, "file_name": "b.jpg", "width": 50, "height": 40, "coco_url": "https://example.com/b.jpg"},
+		{"id": 3, "file_name": "c.jpg", "width": 10, "height": 10}
+	],
+	"categories": [
+		{"id": 1, "name": "animal", "supercategory": "animal"},
+		{"id": 2, "name": "dog", "supercategory": "animal"}
+	],
+	"annotations": [
+		{"id": 1, "image_id": 1, "category_id": 2, "bbox": [10, 10, 20, 30], "segmentation": [], "area": 600, "iscrowd": 0},
+		{"id": 2, "image_id": 2, "category_id": 1, "bbox": [0, 0, 4, 4], "segmentation": [[0, 0, 4, 0, 0, 4]], "area": 8, "iscrowd": 0},
+		{"id": 3, "image_id": 2, "category_id": 2, "bbox": [0, 0, 1, 2], "segmentation": {"size": [40, 50], "counts": [0, 2, 1998]}, "area": 2, "iscrowd": 1},
+		{"id": 4, "image_id": 3, "category_id": 2, "bbox": [1, 1, 2, 2], "segmentation": [], "area": 4, "iscrowd": 0}
+	]
+}`
+
+func TestImportRepository_COCO(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "importer", Email: "importer@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+	stranger := &repository.User{Username: "importstranger", Email: "importstranger@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, stranger); err != nil {
+		t.Fatalf("failed to create stranger: %v", err)
+	}
+	defer repo.Users.Delete(ctx, stranger.ID)
+
+	project := &repository.Project{Name: "legacy", CreatedBy: owner.ID}
+	if err := repo.Projects.Create(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	defer repo.Projects.Delete(ctx, project.ID)
+
+	animal := &repository.Label{ProjectID: project.ID, Name: "animal", Color: "#00ff00"}
+	if err := repo.Labels.Create(ctx, animal); err != nil {
+		t.Fatalf("failed to create label: %v", err)
+	}
+	existing := &repository.Image{
+		UserID:    owner.ID,
+		ProjectID: project.ID,
+		URL:       "https://example.com/a.jpg",
+		Title:     "a.jpg",
+		Metadata:  &repository.ImageMetadata{Width: 100, Height: 100},
+	}
+	if err := repo.Images.Create(ctx, existing); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	var coco dataset.COCO
+	if err := json.Unmarshal([]byte(cocoFixture), &coco); err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+
+	plan, err := dataset.PlanCOCOImport(ctx, &coco, project, owner.ID, 1_000_000, repo.Images, repo.Annotations, repo.Labels)
+	if err != nil {
+		t.Fatalf("failed to plan import: %v", err)
+	}
+	report := plan.Report
+	if report.LabelsMatched != 1 || report.LabelsCreated != 1 {
+		t.Errorf("expected animal matched and dog created, got %d matched and %d created", report.LabelsMatched, report.LabelsCreated)
+	}
+	if report.ImagesMatched != 1 || report.ImagesCreated != 1 || !slices.Equal(report.UnmatchedImages, []string{"c.jpg"}) {
+		t.Errorf("expected a.jpg matched, b.jpg created and c.jpg unmatched, got %+v", report)
+	}
+	if report.Annotations != 3 || report.SkippedAnnotations != 1 || report.ConflictCount != 0 {
+		t.Errorf("expected 3 annotations, 1 skipped and no conflicts, got %+v", report)
+	}
+
+	// planning writes nothing
+	if labels, _ := repo.Labels.GetByProjectID(ctx, project.ID, true); len(labels) != 1 {
+		t.Errorf("expected a dry run to create no labels, got %d", len(labels))
+	}
+
+	if err := repo.Imports.Import(ctx, &plan.Batch); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+
+	labels, err := repo.Labels.GetByProjectID(ctx, project.ID, false)
+	if err != nil {
+		t.Fatalf("failed to list labels: %v", err)
+	}
+	if len(labels) != 2 || labels[1].Name != "dog" || labels[1].ParentID != animal.ID {
+		t.Errorf("expected dog nested under animal, got %+v", labels)
+	}
+
+	list, _, err := repo.Annotations.GetByImageID(ctx, existing.ID, repository.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list annotations: %v", err)
+	}
+	if len(list) != 1 || list[0].X != 10 || list[0].Height != 30 || list[0].LabelID != labels[1].ID || list[0].UserID != owner.ID {
+		t.Errorf("expected the dog box on a.jpg, got %+v", list)
+	}
+
+	created := plan.Batch.Images[0]
+	policy := access.NewPolicy(repo.Images, repo.Annotations, repo.ImageMembers, repo.Projects, repo.ProjectMembers)
+	if _, err := policy.ViewImage(ctx, stranger, created.ID); !errors.Is(err, access.ErrNotFound) {
+		t.Errorf("expected an imported image to be hidden from non-members, got %v", err)
+	}
+	if _, err := policy.ViewImage(ctx, owner, created.ID); err != nil {
+		t.Errorf("expected the importer to view the imported image, got %v", err)
+	}
+
+	list, _, err = repo.Annotations.GetByImageID(ctx, created.ID, repository.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list annotations: %v", err)
+	}
+	if len(list) != 2 || list[0].Geometry.Type != geometry.Polygon || list[1].Geometry.Type != geometry.Mask {
+		t.Fatalf("expected a polygon and a mask on b.jpg, got %+v", list)
+	}
+	mask, err := repo.Annotations.GetMask(ctx, list[1].ID)
+	if err != nil || mask.Area() != 2 {
+		t.Errorf("expected the imported mask of area 2, got %v (%v)", mask, err)
+	}
+
+	// importing the same file again only reports what is already there
+	again, err := dataset.PlanCOCOImport(ctx, &coco, project, owner.ID, 1_000_000, repo.Images, repo.Annotations, repo.Labels)
+	if err != nil {
+		t.Fatalf("failed to plan second import: %v", err)
+	}
+	if again.Report.Annotations != 0 || again.Report.ConflictCount != 3 || again.Report.Conflicts[0].Code != "duplicate_annotation" {
+		t.Errorf("expected the 3 annotations to be reported as duplicates, got %+v", again.Report)
+	}
+}
+
+func TestImportRepository_COCOConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "conflicted", Email: "conflicted@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	project := &repository.Project{Name: "conflicts", CreatedBy: owner.ID}
+	if err := repo.Projects.Create(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	defer repo.Projects.Delete(ctx, project.ID)
+
+	coco := dataset.COCO{
+		Images:     []dataset.COCOImage{{ID: 1, FileName: "x.jpg", Width: 10, Height: 10, URL: "https://example.com/x.jpg"}},
+		Categories: []dataset.COCOCategory{{ID: 1, Name: "car"}},
+		Annotations: []dataset.COCOAnnotation{
+			{ID: 1, ImageID: 1, CategoryID: 9, BBox: [4]float64{0, 0, 1, 1}},
+			{ID: 2, ImageID: 7, CategoryID: 1, BBox: [4]float64{0, 0, 1, 1}},
+			{ID: 3, ImageID: 1, CategoryID: 1, BBox: [4]float64{5, 5, 20, 1}},
+		},
+	}
+
+	plan, err := dataset.PlanCOCOImport(ctx, &coco, project, owner.ID, 1_000_000, repo.Images, repo.Annotations, repo.Labels)
+	if err != nil {
+		t.Fatalf("failed to plan import: %v", err)
+	}
+
+	var codes []string
+	for _, c := range plan.Report.Conflicts {
+		codes = append(codes, c.Code)
+	}
+	if want := []string{"unknown_category", "unknown_image", "invalid_annotation"}; !slices.Equal(codes, want) {
+		t.Errorf("expected conflicts %v, got %v", want, codes)
+	}
+}
+
+// vocFixture holds VOC files as written by the original devkit, with the elements the
+// import ignores.
+var vocFixture = map[string]string{
+	"VOC/Annotations/a.xml": `<annotation>
+	<folder>VOC2007</folder>
+	<filename>a.jpg</filename>
+	<source><database>The VOC2007 Database</database></source>
+	<size><width>100</width><height>80</height><depth>3</depth></size>
+	<segmented>0</segmented>
+	<object>
+		<name>dog</name>
+		<pose>Left</pose>
+		<truncated>1</truncated>
+		<difficult>0</difficult>
+		<bndbox><xmin>11</xmin><ymin>21</ymin><xmax>40</xmax><ymax>80</ymax></bndbox>
+	</object>
+	<object>
+		<name>person</name>
+		<pose>Unspecified</pose>
+		<truncated>0</truncated>
+		<difficult>1</difficult>
+		<bndbox><xmin>1</xmin><ymin>1</ymin><xmax>5.5</xmax><ymax>9</ymax></bndbox>
+		<part><name>head</name><bndbox><xmin>1</xmin><ymin>1</ymin><xmax>3</xmax><ymax>3</ymax></bndbox></part>
+	</object>
+</annotation>`,
+	"VOC/Annotations/missing.xml": `<annotation><filename>missing.jpg</filename>
+	<object><name>dog</name><bndbox><xmin>1</xmin><ymin>1</ymin><xmax>2</xmax><ymax>2</ymax></bndbox></object>
+</annotation>`,
+	"VOC/README.txt": "not an annotation",
+}
+
+func TestImportRepository_VOC(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "vocimporter", Email: "vocimporter@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	project := &repository.Project{Name: "voc", CreatedBy: owner.ID}
+	if err := repo.Projects.Create(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	defer repo.Projects.Delete(ctx, project.ID)
+
+	dog := &repository.Label{ProjectID: project.ID, Name: "dog", Color: "#00ff00"}
+	if err := repo.Labels.Create(ctx, dog); err != nil {
+		t.Fatalf("failed to create label: %v", err)
+	}
+	image := &repository.Image{
+		UserID:    owner.ID,
+		ProjectID: project.ID,
+		URL:       "https://example.com/a.jpg",
+		Title:     "a.jpg",
+		Metadata:  &repository.ImageMetadata{Width: 100, Height: 80},
+	}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	plan, err := dataset.PlanVOCImport(ctx, vocArchive(t, vocFixture), project, owner.ID, repo.Images, repo.Labels)
+	if err != nil {
+		t.Fatalf("failed to plan import: %v", err)
+	}
+	report := plan.Report
+	if report.LabelsMatched != 1 || report.LabelsCreated != 1 || report.ImagesMatched != 1 || report.ImagesCreated != 0 {
+		t.Errorf("expected dog matched, person created and a.jpg matched, got %+v", report)
+	}
+	if report.Annotations != 2 || report.SkippedAnnotations != 1 || !slices.Equal(report.UnmatchedImages, []string{"missing.jpg"}) || report.ConflictCount != 0 {
+		t.Errorf("expected 2 annotations and missing.jpg skipped, got %+v", report)
+	}
+
+	if err := repo.Imports.Import(ctx, &plan.Batch); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+
+	list, _, err := repo.Annotations.GetByImageID(ctx, image.ID, repository.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list annotations: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 annotations on a.jpg, got %d", len(list))
+	}
+	box := list[0]
+	if box.LabelID != dog.ID || box.X != 10 || box.Y != 20 || box.Width != 30 || box.Height != 60 || box.Geometry.Type != geometry.Box {
+		t.Errorf("expected the dog box from 1-based corners, got %+v", box)
+	}
+	if box.Attributes["truncated"] != true || box.Attributes["difficult"] != false || box.Attributes["pose"] != "Left" {
+		t.Errorf("expected the VOC flags as attributes, got %v", box.Attributes)
+	}
+	if person := list[1]; person.Width != 5.5 || person.Attributes["difficult"] != true || person.Attributes["pose"] != nil {
+		t.Errorf("expected the difficult person without a pose, got %+v", person)
+	}
+
+	// exporting writes the flags back
+	var out bytes.Buffer
+	if err := dataset.WriteVOC(&out, image, list, map[string]string{dog.ID: "dog"}); err != nil {
+		t.Fatalf("failed to write VOC: %v", err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("<truncated>1</truncated>")) || !bytes.Contains(out.Bytes(), []byte("<xmin>11</xmin>")) {
+		t.Errorf("expected the imported box and flags in the export:\n%s", out.String())
+	}
+}
+
+func TestImportRepository_VOCConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "vocconflicted", Email: "vocconflicted@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	project := &repository.Project{Name: "voc conflicts", CreatedBy: owner.ID}
+	if err := repo.Projects.Create(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	defer repo.Projects.Delete(ctx, project.ID)
+
+	image := &repository.Image{UserID: owner.ID, ProjectID: project.ID, URL: "https://example.com/x.jpg", Title: "x.jpg"}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	files := map[string]string{
+		"1.xml": "<annotation><filename>x.jpg</filename><size><width>10</width><height>10</height></size>" +
+			"<object><name>car</name><bndbox><xmin>5</xmin><ymin>5</ymin><xmax>2</xmax><ymax>9</ymax></bndbox></object>" +
+			"<object><name>car</name><bndbox><xmin>5</xmin><ymin>5</ymin><xmax>30</xmax><ymax>9</ymax></bndbox></object>" +
+			"<object><bndbox><xmin>1</xmin><ymin>1</ymin><xmax>2</xmax><ymax>2</ymax></bndbox></object></annotation>",
+		"2.xml": "<annotation><filename>x.jpg</filename></annotation>",
+		"3.xml": "<annotation><filename>",
+	}
+	plan, err := dataset.PlanVOCImport(ctx, vocArchive(t, files), project, owner.ID, repo.Images, repo.Labels)
+	if err != nil {
+		t.Fatalf("failed to plan import: %v", err)
+	}
+
+	var codes []string
+	for _, c := range plan.Report.Conflicts {
+		codes = append(codes, c.Code)
+	}
+	if want := []string{"invalid_annotation", "invalid_annotation", "invalid_name", "duplicate_image", "invalid_file"}; !slices.Equal(codes, want) {
+		t.Errorf("expected conflicts %v, got %v", want, codes)
+	}
+}
+
+// vocArchive zips the files, in name order.
+func vocArchive(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		f.Write([]byte(files[name]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	return archive
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+func TestLabelRepository_Taxonomy(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "labelowner", Email: "labelowner@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	project := &repository.Project{Name: "labels", CreatedBy: owner.ID}
+	if err := repo.Projects.Create(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	defer repo.Projects.Delete(ctx, project.ID)
+
+	animal := &repository.Label{ProjectID: project.ID, Name: "animal", Color: "#00ff00"}
+	if err := repo.Labels.Create(ctx, animal); err != nil {
+		t.Fatalf("failed to create label: %v", err)
+	}
+	cat := &repository.Label{ProjectID: project.ID, ParentID: animal.ID, Name: "cat", Color: "#ff0000"}
+	if err := repo.Labels.Create(ctx, cat); err != nil {
+		t.Fatalf("failed to create child label: %v", err)
+	}
+
+	duplicate := &repository.Label{ProjectID: project.ID, Name: "cat", Color: "#ff0000"}
+	var dupErr *repository.DuplicateError
+	if err := repo.Labels.Create(ctx, duplicate); !errors.As(err, &dupErr) || dupErr.Field != "name" {
+		t.Errorf("expected duplicate name error, got %v", err)
+	}
+
+	animal.ParentID = cat.ID
+	if err := repo.Labels.Update(ctx, animal); !errors.Is(err, repository.ErrLabelCycle) {
+		t.Errorf("expected ErrLabelCycle when nesting a label under its child, got %v", err)
+	}
+	animal.ParentID = ""
+
+	if err := repo.Labels.SetArchived(ctx, cat, true); err != nil {
+		t.Fatalf("failed to archive label: %v", err)
+	}
+	if cat.ArchivedAt == "" {
+		t.Error("expected archived_at to be set")
+	}
+	active, err := repo.Labels.GetByProjectID(ctx, project.ID, false)
+	if err != nil {
+		t.Fatalf("failed to list labels: %v", err)
+	}
+	if len(active) != 1 || active[0].ID != animal.ID {
+		t.Errorf("expected only the active label, got %+v", active)
+	}
+	if err := repo.Labels.Create(ctx, duplicate); err != nil {
+		t.Errorf("expected archived name to be reusable, got %v", err)
+	}
+	if err := repo.Labels.SetArchived(ctx, cat, false); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("expected restoring a taken name to fail, got %v", err)
+	}
+}
+
+func TestLabelRepository_Merge(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "mergeowner", Email: "mergeowner@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	project := &repository.Project{Name: "merge", CreatedBy: owner.ID}
+	if err := repo.Projects.Create(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	defer repo.Projects.Delete(ctx, project.ID)
+
+	car := &repository.Label{ProjectID: project.ID, Name: "car", Color: "#0000ff"}
+	auto := &repository.Label{ProjectID: project.ID, Name: "automobile", Color: "#0000ff"}
+	for _, l := range []*repository.Label{car, auto} {
+		if err := repo.Labels.Create(ctx, l); err != nil {
+			t.Fatalf("failed to create label: %v", err)
+		}
+	}
+	sedan := &repository.Label{ProjectID: project.ID, ParentID: auto.ID, Name: "sedan", Color: "#0000ff"}
+	if err := repo.Labels.Create(ctx, sedan); err != nil {
+		t.Fatalf("failed to create child label: %v", err)
+	}
+
+	image := &repository.Image{UserID: owner.ID, ProjectID: project.ID, URL: "https://example.com/road.png", Title: "road"}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	annotation := &repository.Annotation{ImageID: image.ID, UserID: owner.ID, LabelID: auto.ID, Width: 1, Height: 1}
+	if err := repo.Annotations.Create(ctx, annotation); err != nil {
+		t.Fatalf("failed to create annotation: %v", err)
+	}
+
+	if _, err := repo.Labels.Merge(ctx, auto.ID, sedan.ID); !errors.Is(err, repository.ErrLabelCycle) {
+		t.Errorf("expected ErrLabelCycle when merging into a descendant, got %v", err)
+	}
+
+	moved, err := repo.Labels.Merge(ctx, auto.ID, car.ID)
+	if err != nil {
+		t.Fatalf("failed to merge labels: %v", err)
+	}
+	if moved != 1 {
+		t.Errorf("expected 1 relabeled annotation, got %d", moved)
+	}
+
+	got, err := repo.Annotations.GetByID(ctx, annotation.ID)
+	if err != nil {
+		t.Fatalf("failed to get annotation: %v", err)
+	}
+	if got.LabelID != car.ID {
+		t.Errorf("expected annotation label %s, got %s", car.ID, got.LabelID)
+	}
+	child, err := repo.Labels.GetByID(ctx, sedan.ID)
+	if err != nil {
+		t.Fatalf("failed to get child label: %v", err)
+	}
+	if child.ParentID != car.ID {
+		t.Errorf("expected child reparented to %s, got %s", car.ID, child.ParentID)
+	}
+	if _, err := repo.Labels.GetByID(ctx, auto.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected merged label to be deleted, got %v", err)
+	}
+}
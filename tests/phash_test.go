@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/phash"
+	"golang.org/x/image/draw"
+)
+
+func TestPerceptualHash(t *testing.T) {
+	original := testPattern(640, 480, false)
+
+	// a downscaled, JPEG re-encoded copy must stay close to the original
+	small := image.NewRGBA(image.Rect(0, 0, 200, 150))
+	draw.CatmullRom.Scale(small, small.Bounds(), original, original.Bounds(), draw.Src, nil)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, small, &jpeg.Options{Quality: 60}); err != nil {
+		t.Fatalf("failed to encode jpeg: %v", err)
+	}
+	copied, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode jpeg: %v", err)
+	}
+
+	h1, h2 := phash.Compute(original), phash.Compute(copied)
+	if d := phash.Distance(h1, h2); d > 8 {
+		t.Errorf("expected a resized copy within 8 bits, got %d", d)
+	}
+
+	h3 := phash.Compute(testPattern(640, 480, true))
+	if d := phash.Distance(h1, h3); d < 12 {
+		t.Errorf("expected a different image to be far apart, got %d", d)
+	}
+
+	parsed, err := phash.Parse(h1.String())
+	if err != nil || parsed != h1 {
+		t.Errorf("expected %s to round-trip, got %s, %v", h1, parsed, err)
+	}
+}
+
+func TestPerceptualHashCluster(t *testing.T) {
+	items := []phash.Item{
+		{ID: "a", Hash: 0x0000000000000000},
+		{ID: "b", Hash: 0x0000000000000007}, // 3 bits from a
+		{ID: "c", Hash: 0x000000000000003f}, // 3 bits from b, 6 from a
+		{ID: "d", Hash: 0xffffffffffffffff},
+		{ID: "e", Hash: 0xfffffffffffffff0},
+		{ID: "f", Hash: 0x00ff00ff00ff00ff},
+	}
+
+	clusters := phash.Cluster(items, 4)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %+v", clusters)
+	}
+	if ids := clusterIDs(clusters[0]); ids != "abc" {
+		t.Errorf("expected a transitive cluster abc, got %s", ids)
+	}
+	if ids := clusterIDs(clusters[1]); ids != "de" {
+		t.Errorf("expected cluster de, got %s", ids)
+	}
+
+	if clusters := phash.Cluster(items, 0); len(clusters) != 0 {
+		t.Errorf("expected no clusters at threshold 0, got %+v", clusters)
+	}
+}
+
+func clusterIDs(items []phash.Item) string {
+	var ids string
+	for _, item := range items {
+		ids += item.ID
+	}
+	return ids
+}
+
+// testPattern draws a few shapes; inverted swaps light and dark halves.
+func testPattern(w, h int, inverted bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / w)
+			if (y < h/2) != inverted {
+				v = 255 - v
+			}
+			if (x-w/3)*(x-w/3)+(y-h/3)*(y-h/3) < (h/5)*(h/5) {
+				v = 20
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
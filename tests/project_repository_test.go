@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/server/access"
+)
+
+func TestProjectRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "projectowner", Email: "projectowner@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	defer repo.Users.Delete(ctx, owner.ID)
+
+	project := &repository.Project{Name: "birds", Description: "bird detection", CreatedBy: owner.ID}
+	if err := repo.Projects.Create(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	if project.ID == "" || project.Role != repository.ProjectOwner {
+		t.Fatalf("expected created project with owner role, got %+v", project)
+	}
+
+	role, err := repo.ProjectMembers.GetRole(ctx, project.ID, owner.ID)
+	if err != nil || role != repository.ProjectOwner {
+		t.Errorf("expected creator to be owner, got %q, %v", role, err)
+	}
+
+	project.Name = "seabirds"
+	if err := repo.Projects.Update(ctx, project); err != nil {
+		t.Fatalf("failed to update project: %v", err)
+	}
+	got, err := repo.Projects.GetByID(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("failed to get project: %v", err)
+	}
+	if got.Name != "seabirds" {
+		t.Errorf("expected name %q, got %q", "seabirds", got.Name)
+	}
+
+	projects, _, err := repo.Projects.GetAllForUser(ctx, owner.ID, repository.ListOptions{Limit: repository.MaxLimit})
+	if err != nil {
+		t.Fatalf("failed to list projects: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != project.ID || projects[0].Role != repository.ProjectOwner {
+		t.Errorf("expected the one owned project, got %+v", projects)
+	}
+
+	if err := repo.Projects.Delete(ctx, project.ID); err != nil {
+		t.Fatalf("failed to delete project: %v", err)
+	}
+	if _, err := repo.Projects.GetByID(ctx, project.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestProjectRepository_Members(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "memberowner", Email: "memberowner@example.com", Password: "secretpassword"}
+	editor := &repository.User{Username: "membereditor", Email: "membereditor@example.com", Password: "secretpassword"}
+	for _, u := range []*repository.User{owner, editor} {
+		if err := repo.Users.Create(ctx, u); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		defer repo.Users.Delete(ctx, u.ID)
+	}
+
+	project := &repository.Project{Name: "members", CreatedBy: owner.ID}
+	if err := repo.Projects.Create(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	defer repo.Projects.Delete(ctx, project.ID)
+
+	if err := repo.ProjectMembers.SetRole(ctx, project.ID, editor.ID, repository.ProjectEditor); err != nil {
+		t.Fatalf("failed to add editor: %v", err)
+	}
+
+	members, err := repo.ProjectMembers.GetAll(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("failed to list members: %v", err)
+	}
+	if len(members) != 2 || members[0].UserID != owner.ID {
+		t.Errorf("expected owner listed first among two members, got %+v", members)
+	}
+
+	if err := repo.ProjectMembers.SetRole(ctx, project.ID, owner.ID, repository.ProjectViewer); !errors.Is(err, repository.ErrLastOwner) {
+		t.Errorf("expected ErrLastOwner when demoting the last owner, got %v", err)
+	}
+	if err := repo.ProjectMembers.Remove(ctx, project.ID, owner.ID); !errors.Is(err, repository.ErrLastOwner) {
+		t.Errorf("expected ErrLastOwner when removing the last owner, got %v", err)
+	}
+
+	if err := repo.ProjectMembers.SetRole(ctx, project.ID, editor.ID, repository.ProjectOwner); err != nil {
+		t.Fatalf("failed to promote editor: %v", err)
+	}
+	if err := repo.ProjectMembers.Remove(ctx, project.ID, owner.ID); err != nil {
+		t.Errorf("expected removing one of two owners to succeed, got %v", err)
+	}
+	if _, err := repo.ProjectMembers.GetRole(ctx, project.ID, owner.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a removed member, got %v", err)
+	}
+}
+
+func TestPolicy_ProjectImage(t *testing.T) {
+	ctx := context.Background()
+
+	owner := &repository.User{Username: "projpolicyowner", Email: "projpolicyowner@example.com", Password: "secretpassword"}
+	editor := &repository.User{Username: "projpolicyeditor", Email: "projpolicyeditor@example.com", Password: "secretpassword"}
+	viewer := &repository.User{Username: "projpolicyviewer", Email: "projpolicyviewer@example.com", Password: "secretpassword"}
+	stranger := &repository.User{Username: "projpolicystranger", Email: "projpolicystranger@example.com", Password: "secretpassword"}
+	for _, u := range []*repository.User{owner, editor, viewer, stranger} {
+		if err := repo.Users.Create(ctx, u); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		defer repo.Users.Delete(ctx, u.ID)
+	}
+
+	project := &repository.Project{Name: "policy", CreatedBy: owner.ID}
+	if err := repo.Projects.Create(ctx, project); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	defer repo.Projects.Delete(ctx, project.ID)
+	if err := repo.ProjectMembers.SetRole(ctx, project.ID, editor.ID, repository.ProjectEditor); err != nil {
+		t.Fatalf("failed to add editor: %v", err)
+	}
+	if err := repo.ProjectMembers.SetRole(ctx, project.ID, viewer.ID, repository.ProjectViewer); err != nil {
+		t.Fatalf("failed to add viewer: %v", err)
+	}
+
+	image := &repository.Image{UserID: owner.ID, ProjectID: project.ID, URL: "https://example.com/project.png", Title: "project"}
+	if err := repo.Images.Create(ctx, image); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	policy := access.NewPolicy(repo.Images, repo.Annotations, repo.ImageMembers, repo.Projects, repo.ProjectMembers)
+
+	if _, err := policy.ViewImage(ctx, viewer, image.ID); err != nil {
+		t.Errorf("expected project viewer to view image, got %v", err)
+	}
+	if _, err := policy.ViewImage(ctx, stranger, image.ID); !errors.Is(err, access.ErrNotFound) {
+		t.Errorf("expected stranger to get not found, got %v", err)
+	}
+	if _, err := policy.AnnotateImage(ctx, viewer, image.ID); !errors.Is(err, access.ErrForbidden) {
+		t.Errorf("expected viewer to be forbidden from annotating project image, got %v", err)
+	}
+	if _, err := policy.AnnotateImage(ctx, editor, image.ID); err != nil {
+		t.Errorf("expected editor to annotate project image, got %v", err)
+	}
+	if _, err := policy.AnnotateImage(ctx, stranger, image.ID); !errors.Is(err, access.ErrNotFound) {
+		t.Errorf("expected stranger to get not found when annotating, got %v", err)
+	}
+	// an uploader's access follows their project role, not the upload
+	uploaded := &repository.Image{UserID: editor.ID, ProjectID: project.ID, URL: "https://example.com/uploaded.png", Title: "uploaded"}
+	if err := repo.Images.Create(ctx, uploaded); err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	annotation := &repository.Annotation{ImageID: uploaded.ID, UserID: editor.ID, Width: 1, Height: 1}
+	if err := repo.Annotations.Create(ctx, annotation); err != nil {
+		t.Fatalf("failed to create annotation: %v", err)
+	}
+	if _, err := policy.ManageImage(ctx, editor, uploaded.ID); err != nil {
+		t.Errorf("expected editor to manage their upload, got %v", err)
+	}
+	if _, err := policy.ManageImage(ctx, editor, image.ID); !errors.Is(err, access.ErrForbidden) {
+		t.Errorf("expected editor to be forbidden from managing another upload, got %v", err)
+	}
+	if err := repo.ProjectMembers.SetRole(ctx, project.ID, editor.ID, repository.ProjectViewer); err != nil {
+		t.Fatalf("failed to demote editor: %v", err)
+	}
+	if _, err := policy.ManageImage(ctx, editor, uploaded.ID); !errors.Is(err, access.ErrForbidden) {
+		t.Errorf("expected demoted uploader to be forbidden from managing image, got %v", err)
+	}
+	if _, _, err := policy.EditAnnotation(ctx, editor, annotation.ID); !errors.Is(err, access.ErrForbidden) {
+		t.Errorf("expected demoted author to be forbidden from editing annotation, got %v", err)
+	}
+	if err := repo.ProjectMembers.Remove(ctx, project.ID, editor.ID); err != nil {
+		t.Fatalf("failed to remove editor: %v", err)
+	}
+	if _, err := policy.ViewImage(ctx, editor, uploaded.ID); !errors.Is(err, access.ErrNotFound) {
+		t.Errorf("expected removed uploader to get not found, got %v", err)
+	}
+	removed, _, err := repo.Images.GetAllVisibleTo(ctx, editor.ID, repository.ListOptions{
+		Limit:   repository.MaxLimit,
+		Filters: map[string]string{"project_id": project.ID},
+	})
+	if err != nil {
+		t.Fatalf("failed to list visible images: %v", err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("expected no project images visible to a removed member, got %d", len(removed))
+	}
+
+	if _, err := policy.EditProject(ctx, viewer, project.ID); !errors.Is(err, access.ErrForbidden) {
+		t.Errorf("expected viewer to be forbidden from editing project, got %v", err)
+	}
+	if _, err := policy.ViewProject(ctx, stranger, project.ID); !errors.Is(err, access.ErrNotFound) {
+		t.Errorf("expected stranger to get project not found, got %v", err)
+	}
+
+	visible, _, err := repo.Images.GetAllVisibleTo(ctx, viewer.ID, repository.ListOptions{
+		Limit:   repository.MaxLimit,
+		Filters: map[string]string{"project_id": project.ID},
+	})
+	if err != nil {
+		t.Fatalf("failed to list visible images: %v", err)
+	}
+	if len(visible) != 2 || !containsImage(visible, image.ID) || !containsImage(visible, uploaded.ID) {
+		t.Errorf("expected the project images to be visible to the viewer, got %d images", len(visible))
+	}
+}
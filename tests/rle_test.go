@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/lib/rle"
+)
+
+// maskImage draws a 4x3 mask with a 2x2 square at (1, 1) and a pixel at (3, 0).
+func maskImage() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 4, 3))
+	for _, p := range [][2]int{{1, 1}, {2, 1}, {1, 2}, {2, 2}, {3, 0}} {
+		img.SetGray(p[0], p[1], color.Gray{Y: 255})
+	}
+	return img
+}
+
+func TestRLE_FromImage(t *testing.T) {
+	m := rle.FromImage(maskImage())
+
+	// column-major: the foreground of column 2 runs on into the top of column 3
+	want := []uint32{4, 2, 1, 3, 2}
+	if m.Height != 3 || m.Width != 4 || !slices.Equal(m.Counts, want) {
+		t.Fatalf("expected 3x4 mask with counts %v, got %dx%d %v", want, m.Height, m.Width, m.Counts)
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("expected valid mask, got %v", err)
+	}
+	if m.Area() != 5 {
+		t.Errorf("expected area 5, got %d", m.Area())
+	}
+
+	x, y, w, h, ok := m.Bounds()
+	if !ok || x != 1 || y != 0 || w != 3 || h != 3 {
+		t.Errorf("expected bounds (1, 0, 3, 3), got (%d, %d, %d, %d, %v)", x, y, w, h, ok)
+	}
+
+	if got := m.Image(); !slices.Equal(got.Pix, maskImage().Pix) {
+		t.Errorf("expected decoded pixels %v, got %v", maskImage().Pix, got.Pix)
+	}
+}
+
+func TestRLE_EmptyMask(t *testing.T) {
+	m := rle.FromImage(image.NewGray(image.Rect(0, 0, 5, 2)))
+	if !slices.Equal(m.Counts, []uint32{10}) || m.Area() != 0 {
+		t.Errorf("expected a single background run, got %v", m.Counts)
+	}
+	if _, _, _, _, ok := m.Bounds(); ok {
+		t.Error("expected no bounds for an empty mask")
+	}
+}
+
+func TestRLE_Compressed(t *testing.T) {
+	// the fourth count is stored relative to the second, here as the negative delta -2
+	m := &rle.RLE{Height: 11, Width: 1, Counts: []uint32{5, 3, 2, 1}}
+	if s := m.String(); s != "532N" {
+		t.Errorf("expected %q, got %q", "532N", s)
+	}
+
+	large := rle.FromImage(maskImage())
+	large.Height, large.Width = 300, 400
+	large.Counts[len(large.Counts)-1] += 300*400 - 12
+	parsed, err := rle.Parse(large.Height, large.Width, large.String())
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", large.String(), err)
+	}
+	if !slices.Equal(parsed.Counts, large.Counts) {
+		t.Errorf("expected counts %v after round trip, got %v", large.Counts, parsed.Counts)
+	}
+
+	if _, err := rle.Parse(11, 1, "53"); !errors.Is(err, rle.ErrInvalid) {
+		t.Errorf("expected ErrInvalid for counts not covering the mask, got %v", err)
+	}
+	if _, err := rle.Parse(11, 1, "5 3"); !errors.Is(err, rle.ErrInvalid) {
+		t.Errorf("expected ErrInvalid for an unexpected character, got %v", err)
+	}
+}
+
+func TestRLE_JSON(t *testing.T) {
+	m := rle.FromImage(maskImage())
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("failed to marshal mask: %v", err)
+	}
+	if want := `{"size":[3,4],"counts":"` + m.String() + `"}`; string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+
+	var decoded rle.RLE
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal compressed mask: %v", err)
+	}
+	if !slices.Equal(decoded.Counts, m.Counts) {
+		t.Errorf("expected counts %v, got %v", m.Counts, decoded.Counts)
+	}
+
+	// uncompressed RLE as written by pycocotools for iscrowd annotations
+	if err := json.Unmarshal([]byte(`{"size":[3,4],"counts":[4,2,1,3,2]}`), &decoded); err != nil {
+		t.Fatalf("failed to unmarshal uncompressed mask: %v", err)
+	}
+	if decoded.Area() != 5 {
+		t.Errorf("expected area 5, got %d", decoded.Area())
+	}
+
+	err = json.Unmarshal([]byte(`{"size":[3,4],"counts":[4,2]}`), &decoded)
+	if !errors.Is(err, rle.ErrInvalid) {
+		t.Errorf("expected ErrInvalid for short counts, got %v", err)
+	}
+}
+
+func TestRLE_TooManyPixels(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          bool
+	}{
+		{10_000, 10_000, false},
+		{10_001, 10_000, true},
+		{math.MaxInt, 2, true},
+		{math.MaxInt, 0, false},
+	}
+	for _, tt := range tests {
+		if got := rle.TooManyPixels(tt.width, tt.height); got != tt.want {
+			t.Errorf("TooManyPixels(%d, %d) = %v, want %v", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+)
+
+func TestRoleRepository_UserRoles(t *testing.T) {
+	ctx := context.Background()
+
+	user := &repository.User{Username: "roleduser", Email: "roleduser@example.com", Password: "secretpassword"}
+	if err := repo.Users.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	defer repo.Users.Delete(ctx, user.ID)
+
+	roles, err := repo.Roles.GetByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("failed to get roles: %v", err)
+	}
+	if !slices.Equal(roles, []string{repository.DefaultRole}) {
+		t.Errorf("expected new user to have the default role, got %v", roles)
+	}
+
+	if err := repo.Roles.SetUserRoles(ctx, user.ID, []string{repository.RoleReviewer}); err != nil {
+		t.Fatalf("failed to set roles: %v", err)
+	}
+
+	permissions, err := repo.Roles.GetPermissions(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("failed to get permissions: %v", err)
+	}
+	if !slices.Contains(permissions, repository.PermAnnotationsReview) || slices.Contains(permissions, repository.PermAnnotationsWrite) {
+		t.Errorf("expected reviewer permissions only, got %v", permissions)
+	}
+
+	if err := repo.Roles.SetUserRoles(ctx, user.ID, []string{"superuser"}); err == nil {
+		t.Error("expected unknown role to be rejected")
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+)
+
+func TestLocalStorage(t *testing.T) {
+	blob, err := storage.NewLocal(t.TempDir(), "/files")
+	if err != nil {
+		t.Fatalf("failed to create local storage: %v", err)
+	}
+	testBlob(t, blob)
+
+	if err := blob.Put(context.Background(), "../escape.png", bytes.NewReader(nil), 0, "image/png"); err == nil {
+		t.Error("expected a key escaping the root to be rejected")
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	endpoint := os.Getenv("TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("TEST_S3_ENDPOINT not set; start MinIO with docker compose to run this test")
+	}
+
+	blob, err := storage.NewS3(context.Background(), storage.S3Options{
+		Endpoint:  endpoint,
+		AccessKey: os.Getenv("TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("TEST_S3_SECRET_KEY"),
+		Bucket:    "annotatex-test",
+	})
+	if err != nil {
+		t.Fatalf("failed to create s3 storage: %v", err)
+	}
+	testBlob(t, blob)
+}
+
+func testBlob(t *testing.T, blob storage.Blob) {
+	ctx := context.Background()
+	key := "images/te/test.png"
+	content := []byte("not really a png")
+
+	if err := blob.Ping(ctx); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+
+	if err := blob.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "image/png"); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	rc, err := blob.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("expected %q, got %q", content, got)
+	}
+
+	if blob.URL(key) == "" {
+		t.Error("expected a non-empty URL")
+	}
+
+	if err := blob.Delete(ctx, key); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := blob.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := blob.Delete(ctx, key); err != nil {
+		t.Errorf("deleting a missing object should succeed, got %v", err)
+	}
+}
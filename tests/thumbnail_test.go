@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/Agero19/AnnotateX-api/internal/repository"
+	"github.com/Agero19/AnnotateX-api/internal/storage"
+	"github.com/Agero19/AnnotateX-api/internal/thumbnail"
+)
+
+func TestThumbnailGenerate(t *testing.T) {
+	ctx := context.Background()
+
+	blob, err := storage.NewLocal(t.TempDir(), "/files")
+	if err != nil {
+		t.Fatalf("failed to create local storage: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2000, 1000))); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	key := "images/ab/abcdef.png"
+	if err := blob.Put(ctx, key, &buf, int64(buf.Len()), "image/png"); err != nil {
+		t.Fatalf("failed to store original: %v", err)
+	}
+
+	img := &repository.Image{
+		ID:        "1",
+		ObjectKey: key,
+		// rotated 90 degrees clockwise, so the thumbnails are portrait
+		Metadata: &repository.ImageMetadata{Width: 2000, Height: 1000, Orientation: 6},
+	}
+
+	thumbnails, err := thumbnail.Generate(ctx, blob, img)
+	if err != nil {
+		t.Fatalf("failed to generate thumbnails: %v", err)
+	}
+	if len(thumbnails) != len(thumbnail.Sizes) {
+		t.Fatalf("expected %d thumbnails, got %d", len(thumbnail.Sizes), len(thumbnails))
+	}
+
+	for _, th := range thumbnails {
+		if th.Height != th.Size || th.Width != th.Size/2 {
+			t.Errorf("expected %dx%d for size %d, got %dx%d", th.Size/2, th.Size, th.Size, th.Width, th.Height)
+		}
+		if th.ObjectKey != thumbnail.Key(key, th.Size, ".jpg") {
+			t.Errorf("unexpected key %s", th.ObjectKey)
+		}
+
+		rc, err := blob.Get(ctx, th.ObjectKey)
+		if err != nil {
+			t.Fatalf("thumbnail %s not stored: %v", th.ObjectKey, err)
+		}
+		cfg, format, err := image.DecodeConfig(rc)
+		rc.Close()
+		if err != nil || format != "jpeg" || cfg.Width != th.Width {
+			t.Errorf("unexpected stored thumbnail %s: %s %+v %v", th.ObjectKey, format, cfg, err)
+		}
+	}
+}
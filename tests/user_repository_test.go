@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"log"
 	"os"
 	"testing"
@@ -95,6 +97,8 @@ func TestMain(m *testing.M) {
 }
 
 func TestUserRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+
 	var createdUser repository.User
 
 	t.Run("Create", func(t *testing.T) {
@@ -103,7 +107,7 @@ func TestUserRepository_CRUD(t *testing.T) {
 			Email:    "testuser@example.com",
 			Password: "secretpassword",
 		}
-		err := repo.Users.Create(user)
+		err := repo.Users.Create(ctx, user)
 		if err != nil {
 			t.Fatalf("failed to create user: %v", err)
 		}
@@ -114,7 +118,7 @@ func TestUserRepository_CRUD(t *testing.T) {
 	})
 
 	t.Run("GetAll", func(t *testing.T) {
-		users, err := repo.Users.GetAll()
+		users, _, err := repo.Users.GetAll(ctx, repository.ListOptions{})
 		if err != nil {
 			t.Fatalf("failed to get all users: %v", err)
 		}
@@ -124,7 +128,7 @@ func TestUserRepository_CRUD(t *testing.T) {
 	})
 
 	t.Run("GetByID", func(t *testing.T) {
-		user, err := repo.Users.GetByID(createdUser.ID)
+		user, err := repo.Users.GetByID(ctx, createdUser.ID)
 		if err != nil {
 			t.Fatalf("failed to get user by id: %v", err)
 		}
@@ -138,26 +142,79 @@ func TestUserRepository_CRUD(t *testing.T) {
 		createdUser.Email = "updateduser@example.com"
 		createdUser.Password = "newsecret"
 
-		err := repo.Users.Update(&createdUser)
+		err := repo.Users.Update(ctx, &createdUser)
 		if err != nil {
 			t.Fatalf("failed to update user: %v", err)
 		}
 
-		updated, _ := repo.Users.GetByID(createdUser.ID)
+		updated, _ := repo.Users.GetByID(ctx, createdUser.ID)
 		if updated.Username != "updatedname" {
 			t.Errorf("expected username to be updated, got %s", updated.Username)
 		}
 	})
 
 	t.Run("Delete", func(t *testing.T) {
-		err := repo.Users.Delete(createdUser.ID)
+		err := repo.Users.Delete(ctx, createdUser.ID)
 		if err != nil {
 			t.Fatalf("failed to delete user: %v", err)
 		}
 
-		deleted, _ := repo.Users.GetByID(createdUser.ID)
+		deleted, _ := repo.Users.GetByID(ctx, createdUser.ID)
 		if deleted != nil {
 			t.Errorf("expected user to be deleted")
 		}
 	})
 }
+
+func TestUserRepository_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	user := &repository.User{
+		Username: "uniqueuser",
+		Email:    "uniqueuser@example.com",
+		Password: "secretpassword",
+	}
+	if err := repo.Users.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	defer repo.Users.Delete(ctx, user.ID)
+
+	t.Run("DuplicateEmail", func(t *testing.T) {
+		err := repo.Users.Create(ctx, &repository.User{
+			Username: "otheruser",
+			Email:    user.Email,
+			Password: "secretpassword",
+		})
+		var dup *repository.DuplicateError
+		if !errors.As(err, &dup) || dup.Field != "email" {
+			t.Fatalf("expected duplicate email error, got %v", err)
+		}
+		if !errors.Is(err, repository.ErrDuplicate) {
+			t.Error("expected error to match ErrDuplicate")
+		}
+	})
+
+	t.Run("DuplicateUsername", func(t *testing.T) {
+		err := repo.Users.Create(ctx, &repository.User{
+			Username: user.Username,
+			Email:    "otheruser@example.com",
+			Password: "secretpassword",
+		})
+		var dup *repository.DuplicateError
+		if !errors.As(err, &dup) || dup.Field != "username" {
+			t.Fatalf("expected duplicate username error, got %v", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		if _, err := repo.Users.GetByID(ctx, "0"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.Users.GetByID(ctx, "not-a-number"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for malformed id, got %v", err)
+		}
+		if err := repo.Users.Delete(ctx, "0"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound on delete, got %v", err)
+		}
+	})
+}